@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	uci "github.com/hultan/chess/internal/chess.uci"
+)
+
+func main() {
+	if err := uci.New(os.Stdin, os.Stdout).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to read commands : "+err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	xboard "github.com/hultan/chess/internal/chess.xboard"
+)
+
+func main() {
+	if err := xboard.New(os.Stdin, os.Stdout).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to read commands : "+err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	chess "github.com/hultan/chess/internal/chess.engine"
+)
+
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+func main() {
+	fen := flag.String("fen", startFEN, "the position to count moves from")
+	depth := flag.Int("depth", 4, "the depth to count moves to")
+	divide := flag.Bool("divide", false, "print the node count below each move")
+	flag.Parse()
+
+	b, err := chess.FromFEN(*fen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to parse FEN : "+err.Error())
+		os.Exit(1)
+	}
+
+	start := time.Now()
+	var nodes uint64
+	if *divide {
+		nodes = printDivide(b, *depth)
+	} else {
+		nodes = chess.Perft(b, *depth)
+	}
+	elapsed := time.Since(start)
+
+	fmt.Printf("Nodes : %d\n", nodes)
+	fmt.Printf("Time : %v\n", elapsed.Round(time.Millisecond))
+	if elapsed > 0 {
+		fmt.Printf("NPS : %d\n", int64(float64(nodes)/elapsed.Seconds()))
+	}
+}
+
+// printDivide prints the node count below each move, sorted
+// by move, and returns the total node count
+func printDivide(b *chess.Board, depth int) uint64 {
+	divide := chess.PerftDivide(b, depth)
+
+	moves := make([]chess.Move, 0, len(divide))
+	for m := range divide {
+		moves = append(moves, m)
+	}
+	sort.Slice(moves, func(i, j int) bool { return moves[i].String() < moves[j].String() })
+
+	var total uint64
+	for _, m := range moves {
+		fmt.Printf("%s: %d\n", m, divide[m])
+		total += divide[m]
+	}
+	fmt.Println()
+
+	return total
+}
@@ -0,0 +1,97 @@
+package chess_engine
+
+// IsAttacked returns true if the position pos is attacked by any piece of color by
+func (b *Board) IsAttacked(pos Position, by Color) bool {
+	x, y := pos.ToXY()
+
+	// Pawns attack diagonally forward, so look backwards from pos
+	pawn, dir := PieceWhitePawn, -1
+	if by == ColorBlack {
+		pawn, dir = PieceBlackPawn, 1
+	}
+	for _, dx := range []int{-1, 1} {
+		if onBoard(x+dx, y+dir) && b.Piece(XY(x+dx, y+dir)) == pawn {
+			return true
+		}
+	}
+
+	if b.isAttackedByJump(x, y, by, knightDirections[:], PieceWhiteKnight) {
+		return true
+	}
+	if b.isAttackedByJump(x, y, by, kingDirections[:], PieceWhiteKing) {
+		return true
+	}
+	if b.isAttackedBySlider(x, y, by, bishopDirections[:], PieceWhiteBishop) {
+		return true
+	}
+	if b.isAttackedBySlider(x, y, by, rookDirections[:], PieceWhiteRook) {
+		return true
+	}
+
+	return false
+}
+
+// InCheck returns true if the king of the color to move is in check
+func (b *Board) InCheck() bool {
+	return b.isKingAttacked(b.ToMove())
+}
+
+//
+// Private functions
+//
+
+// isKingAttacked returns true if the king of color c is attacked.
+// Boards without a king (test positions) are never in check.
+func (b *Board) isKingAttacked(c Color) bool {
+	pos, ok := b.kingPosition(c)
+	if !ok {
+		return false
+	}
+	return b.IsAttacked(pos, getOppositeColor(c))
+}
+
+// kingPosition returns the position of the king of color c
+func (b *Board) kingPosition(c Color) (Position, bool) {
+	king := PieceWhiteKing
+	if c == ColorBlack {
+		king = PieceBlackKing
+	}
+	for i := 0; i < 64; i++ {
+		if b.Piece(Pos(i)) == king {
+			return Pos(i), true
+		}
+	}
+	return 0, false
+}
+
+func (b *Board) isAttackedByJump(x, y int, by Color, directions [][2]int, typ Piece) bool {
+	for _, d := range directions {
+		if !onBoard(x+d[0], y+d[1]) {
+			continue
+		}
+		p := b.Piece(XY(x+d[0], y+d[1]))
+		if p != PieceNone && getPieceType(p) == typ && b.ColorFromPiece(p) == by {
+			return true
+		}
+	}
+	return false
+}
+
+// isAttackedBySlider checks the rays from (x, y) for a slider of type typ
+// or a queen, since the queen moves along both kinds of rays
+func (b *Board) isAttackedBySlider(x, y int, by Color, directions [][2]int, typ Piece) bool {
+	for _, d := range directions {
+		for tx, ty := x+d[0], y+d[1]; onBoard(tx, ty); tx, ty = tx+d[0], ty+d[1] {
+			p := b.Piece(XY(tx, ty))
+			if p == PieceNone {
+				continue
+			}
+			t := getPieceType(p)
+			if (t == typ || t == PieceWhiteQueen) && b.ColorFromPiece(p) == by {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package chess_engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoard_IsAttacked(t *testing.T) {
+	b, err := FromFEN("4k3/8/8/3p4/8/2N5/8/R3K2B w - - 0 1")
+	assert.Nil(t, err)
+
+	// White pieces
+	assert.True(t, b.IsAttacked(Alg("a8"), ColorWhite), "rook along the file")
+	assert.True(t, b.IsAttacked(Alg("d1"), ColorWhite), "rook and king")
+	assert.True(t, b.IsAttacked(Alg("b5"), ColorWhite), "knight")
+	assert.True(t, b.IsAttacked(Alg("d5"), ColorWhite), "knight and bishop")
+	assert.False(t, b.IsAttacked(Alg("c6"), ColorWhite), "bishop blocked by pawn")
+	assert.False(t, b.IsAttacked(Alg("h8"), ColorWhite))
+
+	// Black pieces
+	assert.True(t, b.IsAttacked(Alg("c4"), ColorBlack), "pawn")
+	assert.True(t, b.IsAttacked(Alg("e4"), ColorBlack), "pawn")
+	assert.False(t, b.IsAttacked(Alg("d4"), ColorBlack), "pawns don't attack forward")
+	assert.True(t, b.IsAttacked(Alg("d7"), ColorBlack), "king")
+}
+
+func TestBoard_InCheck(t *testing.T) {
+	b := NewBoard(true)
+	assert.False(t, b.InCheck())
+
+	b = b.MovePiece(Alg("e2"), Alg("e4"))
+	b = b.MovePiece(Alg("f7"), Alg("f6"))
+	b = b.MovePiece(Alg("d1"), Alg("h5"))
+	assert.True(t, b.InCheck())
+}
+
+func TestMover_GenerateLegalMoves(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want []string
+	}{
+		{
+			"King can't move into check",
+			"8/8/8/8/8/8/r7/4K3 w - - 0 1",
+			[]string{"e1d1", "e1f1"},
+		},
+		{
+			"Pinned piece can only move along the pin",
+			"4r3/8/8/8/8/8/4R3/4K3 w - - 0 1",
+			[]string{"e1d1", "e1d2", "e1f1", "e1f2", "e2e3", "e2e4", "e2e5", "e2e6", "e2e7", "e2e8"},
+		},
+		{
+			"Check must be resolved",
+			"4k3/8/8/8/8/3n4/8/R3K3 w - - 0 1",
+			[]string{"e1d1", "e1d2", "e1e2", "e1f1"},
+		},
+		{
+			"Capture the checking piece",
+			"4k3/8/8/8/8/8/3q4/R3K3 w - - 0 1",
+			[]string{"e1d2", "e1f1"},
+		},
+		{
+			"Checkmate",
+			"4k3/8/8/8/8/8/3qq3/4K3 w - - 0 1",
+			[]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, movesToStrings(NewMover().GenerateLegalMoves(b)))
+		})
+	}
+}
+
+func TestBoard_MovePieceIllegal(t *testing.T) {
+	b, err := FromFEN("4r3/8/8/8/8/8/4R3/4K3 w - - 0 1")
+	assert.Nil(t, err)
+
+	assert.Panics(t, func() { b.MovePiece(Alg("e2"), Alg("d2")) }, "pinned rook")
+	assert.Panics(t, func() { b.MovePiece(Alg("e1"), Alg("e3")) }, "not a king move")
+	assert.NotPanics(t, func() { b.MovePiece(Alg("e2"), Alg("e8")) })
+}
@@ -22,5 +22,5 @@ func TestBoard_print(t *testing.T) {
 	assert.Equal(t, "r bqkbnr\npppppppp\n  n     \n        \n        \n  N     \nPPPPPPPP\nR BQKBNR\nWhite to move\nCastling : KQkq\nHalf move count : 2\nMove count : 2\n", b.print())
 
 	b = b.MovePiece(Alg("d2"), Alg("d4"))
-	assert.Equal(t, "r bqkbnr\npppppppp\n  n     \n        \n   P    \n  N     \nPPP PPPP\nR BQKBNR\nBlack to move\nCastling : KQkq\nEn passant : c3\nHalf move count : 0\nMove count : 2\n", b.print())
+	assert.Equal(t, "r bqkbnr\npppppppp\n  n     \n        \n   P    \n  N     \nPPP PPPP\nR BQKBNR\nBlack to move\nCastling : KQkq\nHalf move count : 0\nMove count : 2\n", b.print())
 }
@@ -11,12 +11,15 @@ import (
 //          - bit 8-11 - castling rights : WK,WQ,BK,BQ
 //          - bit 12-16 - en passant target, can only occur on 16 squares (0-16)
 //                  0    no en passant target
-//                  1-8  rank 3
-//                  9-16 rank 5
+//                  1-8  rank 3 (file a-h)
+//                  9-16 rank 6 (file a-h)
+//                  The target is only set if the en passant capture is legal
 //          - bit 17-31 - move count
+//  - hash  - Zobrist hash of the board, updated incrementally
 type Board struct {
 	board [4]uint64
 	extra uint32
+	hash  uint64
 }
 
 func NewBoard(setup bool) *Board {
@@ -52,6 +55,8 @@ func NewBoard(setup bool) *Board {
 	b.clearEnPassantTarget()
 	b.setMoveCount(1)
 
+	b.hash = b.computeHash()
+
 	return b
 }
 
@@ -79,39 +84,38 @@ func (b *Board) Copy() *Board {
 
 	// Copy extra information
 	nb.extra = b.extra
+	nb.hash = b.hash
 
 	return nb
 }
 
+// MovePiece moves the piece at from to the position to, and panics if the
+// move is not legal. Pawns reaching the last rank are promoted to queens,
+// use MakeMove to promote to other pieces.
 func (b *Board) MovePiece(from, to Position) *Board {
-	if e := b.checkValidMoveBasic(from, to); e != nil {
-		panic(e)
+	promotion := PieceNone
+	if _, y := to.ToXY(); getPieceType(b.Piece(from)) == PieceWhitePawn && (y == 1 || y == 8) {
+		promotion = getColoredPiece(PieceWhiteQueen, b.ToMove())
 	}
 
-	// Create a new board
-	nb := b.Copy()
-
-	// Move piece
-	p := nb.Piece(from)
-	nb.setPiece(p, to)
-	nb.removePiece(from)
-
-	// En passant
-	nb.checkEnPassant(b, from, to)
-
-	// Castling
-	nb.checkCastlingRights(from)
-
-	// Next player to move
-	nb.toggleToMove()
+	nb, err := b.MakeMove(NewMove(from, to, promotion, 0))
+	if err != nil {
+		panic(err)
+	}
 
-	// Adjust move count
-	nb.increaseMoveCount()
+	return nb
+}
 
-	// Adjust half move count
-	nb.increaseHalfMoveCount(b, from, to)
+// MakeMove returns a new board with the move m made, or an error if the
+// move is not legal. The flags of m are ignored, they are taken from the
+// matching legal move instead.
+func (b *Board) MakeMove(m Move) (*Board, error) {
+	legal, e := b.checkValidMove(m)
+	if e != nil {
+		return nil, e
+	}
 
-	return nb
+	return b.makeMove(legal), nil
 }
 
 func (b *Board) Piece(index Position) Piece {
@@ -180,14 +184,96 @@ func (b *Board) setPiece(piece Piece, index Position) {
 	i, m := index/16, index%16
 
 	b.board[i] = b.board[i] | uint64(piece<<(m*4))
+	b.hash ^= zobristPieces[piece][index]
 }
 
 func (b *Board) removePiece(index Position) {
 	i, m := index/16, index%16
 
+	b.hash ^= zobristPieces[b.Piece(index)][index]
 	b.board[i] &= ^uint64(0b1111 << (m * 4))
 }
 
+// makeMove returns a new board with the move applied, without
+// checking that the move is valid
+func (b *Board) makeMove(m Move) *Board {
+	from, to := m.From(), m.To()
+
+	// Create a new board
+	nb := b.Copy()
+
+	// The extra information is hashed again when the move is done
+	nb.hash ^= zobristExtra(nb.extra)
+
+	// Move piece (and remove any captured piece)
+	p := nb.Piece(from)
+	if m.IsPromotion() {
+		p = getColoredPiece(m.Promotion(), b.ToMove())
+	}
+	nb.removePiece(to)
+	nb.setPiece(p, to)
+	nb.removePiece(from)
+
+	// En passant, the captured pawn is not on the to position
+	if m.IsEnPassant() {
+		tx, _ := to.ToXY()
+		_, fy := from.ToXY()
+		nb.removePiece(XY(tx, fy))
+	}
+
+	// Castling, the king has been moved, so move the rook as well
+	if m.IsCastle() {
+		c := getCastling(to)
+		nb.setPiece(nb.Piece(c.rook), c.rookTo)
+		nb.removePiece(c.rook)
+	}
+
+	// Castling rights are lost when the king or a rook leaves
+	// its home square, or when a rook is captured on it
+	nb.checkCastlingRights(from)
+	nb.checkCastlingRights(to)
+
+	// Next player to move
+	nb.toggleToMove()
+
+	// Adjust move count
+	nb.increaseMoveCount()
+
+	// En passant, must be checked after toggling the color to move
+	nb.checkEnPassant(m)
+
+	// Adjust half move count
+	nb.increaseHalfMoveCount(b, from, to)
+
+	nb.hash ^= zobristExtra(nb.extra)
+
+	return nb
+}
+
+// makeNullMove returns a copy of the board where the color to move has
+// passed, used by null move pruning in the search. The half move count is
+// reset, since repetitions over a null move are not real repetitions.
+func (b *Board) makeNullMove() *Board {
+	nb := b.Copy()
+	nb.hash ^= zobristExtra(nb.extra)
+	nb.toggleToMove()
+	nb.clearEnPassantTarget()
+	nb.setHalfMoveCount(0)
+	nb.hash ^= zobristExtra(nb.extra)
+
+	return nb
+}
+
+// checkValidMove returns the legal move matching m, with its flags
+// set, or an error if m is not a legal move
+func (b *Board) checkValidMove(m Move) (Move, error) {
+	if e := b.checkValidMoveBasic(m.From(), m.To()); e != nil {
+		return NoMove, e
+	}
+
+	return b.checkValidMoveLegal(m)
+}
+
 func (b *Board) checkValidMoveBasic(from, to Position) error {
 	f, t := b.Color(from), b.Color(to)
 	if f != b.ToMove() {
@@ -203,6 +289,17 @@ func (b *Board) checkValidMoveBasic(from, to Position) error {
 	return nil
 }
 
+// checkValidMoveLegal returns the legal move matching m, or an error if there is none
+func (b *Board) checkValidMoveLegal(m Move) (Move, error) {
+	for _, legal := range NewMover().GenerateLegalMoves(b) {
+		if legal.sameMove(m) {
+			return legal, nil
+		}
+	}
+
+	return NoMove, errors.New("illegal move")
+}
+
 //
 // To move
 //
@@ -241,6 +338,11 @@ func (b *Board) increaseMoveCount() {
 //
 
 func (b *Board) setHalfMoveCount(c int) {
+	// Only 7 bits are available, but the count is only
+	// needed up to 100 (the fifty-move rule)
+	if c > 127 {
+		c = 127
+	}
 	b.extra &= 0b11111111_11111111_11111111_00000001
 	b.extra |= uint32(c) << 1
 }
@@ -287,28 +389,100 @@ func (b *Board) removeCastlingRights(c castlingRight) {
 	}
 }
 
+// castling describes the squares involved when castling
+//  - king, kingTo - the king moves two squares towards the rook
+//  - rook, rookTo - the rook jumps over the king
+//  - empty - squares between the king and the rook that must be empty
+//  - safe - squares the king passes through, that can't be attacked
+type castling struct {
+	right        castlingRight
+	color        Color
+	king, kingTo Position
+	rook, rookTo Position
+	empty        []Position
+	safe         []Position
+}
+
+var castlings = [4]castling{
+	{CastlingWhiteKing, ColorWhite, Alg("e1"), Alg("g1"), Alg("h1"), Alg("f1"),
+		[]Position{Alg("f1"), Alg("g1")}, []Position{Alg("f1"), Alg("g1")}},
+	{CastlingWhiteQueen, ColorWhite, Alg("e1"), Alg("c1"), Alg("a1"), Alg("d1"),
+		[]Position{Alg("d1"), Alg("c1"), Alg("b1")}, []Position{Alg("d1"), Alg("c1")}},
+	{CastlingBlackKing, ColorBlack, Alg("e8"), Alg("g8"), Alg("h8"), Alg("f8"),
+		[]Position{Alg("f8"), Alg("g8")}, []Position{Alg("f8"), Alg("g8")}},
+	{CastlingBlackQueen, ColorBlack, Alg("e8"), Alg("c8"), Alg("a8"), Alg("d8"),
+		[]Position{Alg("d8"), Alg("c8"), Alg("b8")}, []Position{Alg("d8"), Alg("c8")}},
+}
+
+// getCastling returns the castling where the king moves to kingTo
+func getCastling(kingTo Position) *castling {
+	for i := range castlings {
+		if castlings[i].kingTo == kingTo {
+			return &castlings[i]
+		}
+	}
+	panic("invalid castling in getCastling()")
+}
+
+// canCastle returns true if the color to move can castle right now, i e
+//  1. The castling right has not been lost
+//  2. The king and the rook are on their home squares
+//  3. The squares between them are empty
+//  4. The king is not in check, and does not pass through or end up in check
+func (b *Board) canCastle(c *castling) bool {
+	if c.color != b.ToMove() || !b.CastlingRights(c.right) {
+		return false
+	}
+
+	king, rook := PieceWhiteKing, PieceWhiteRook
+	if c.color == ColorBlack {
+		king, rook = PieceBlackKing, PieceBlackRook
+	}
+	if b.Piece(c.king) != king || b.Piece(c.rook) != rook {
+		return false
+	}
+
+	for _, pos := range c.empty {
+		if b.Piece(pos) != PieceNone {
+			return false
+		}
+	}
+
+	opponent := getOppositeColor(c.color)
+	if b.IsAttacked(c.king, opponent) {
+		return false
+	}
+	for _, pos := range c.safe {
+		if b.IsAttacked(pos, opponent) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (b *Board) resetCastlingRights() {
 	b.extra |= 0b1111_00000000
 }
 
-func (b *Board) checkCastlingRights(from Position) {
-	if from == Alg("a1") {
+func (b *Board) checkCastlingRights(pos Position) {
+	if pos == Alg("a1") {
 		b.removeCastlingRights(CastlingWhiteQueen)
 	}
-	if from == Alg("h1") {
+	if pos == Alg("h1") {
 		b.removeCastlingRights(CastlingWhiteKing)
 	}
-	if from == Alg("e1") {
+	if pos == Alg("e1") {
 		b.removeCastlingRights(CastlingWhiteKing)
 		b.removeCastlingRights(CastlingWhiteQueen)
 	}
-	if from == Alg("a8") {
+	if pos == Alg("a8") {
 		b.removeCastlingRights(CastlingBlackQueen)
 	}
-	if from == Alg("h8") {
+	if pos == Alg("h8") {
 		b.removeCastlingRights(CastlingBlackKing)
 	}
-	if from == Alg("e8") {
+	if pos == Alg("e8") {
 		b.removeCastlingRights(CastlingBlackKing)
 		b.removeCastlingRights(CastlingBlackQueen)
 	}
@@ -318,19 +492,82 @@ func (b *Board) checkCastlingRights(from Position) {
 // En passant
 //
 
-func (b *Board) checkEnPassant(oldBoard *Board, from, to Position) {
-	if oldBoard.Piece(from) == PieceWhitePawn && from >= 8 && from <= 15 && to-from == 16 {
-		b.setEnPassantTarget(from - 8)
+// checkEnPassant sets the en passant target after a double push, if the
+// opponent (now to move) can capture en passant, otherwise it is cleared
+func (b *Board) checkEnPassant(m Move) {
+	b.clearEnPassantTarget()
+	if !m.IsDoublePush() {
 		return
 	}
-	if oldBoard.Piece(from) == PieceBlackPawn && from >= 48 && from <= 55 && from-to == 16 {
-		b.setEnPassantTarget(from - 48 + 8)
-		return
+
+	x, y := m.From().ToXY()
+	if y == 2 {
+		b.setEnPassantTarget(x)
+	} else {
+		b.setEnPassantTarget(x + 8)
+	}
+
+	if !b.canCaptureEnPassant() {
+		b.clearEnPassantTarget()
 	}
-	b.clearEnPassantTarget()
 }
 
-func (b *Board) setEnPassantTarget(i Position) {
+// canCaptureEnPassant returns true if the color to move has a
+// legal en passant capture on the en passant target
+func (b *Board) canCaptureEnPassant() bool {
+	target, ok := b.enPassantPosition()
+	if !ok {
+		return false
+	}
+
+	c := b.ToMove()
+	x, y := target.ToXY()
+
+	// White captures towards rank 6, black towards rank 3
+	pawn, enemy, dir := PieceWhitePawn, PieceBlackPawn, -1
+	if c == ColorBlack {
+		pawn, enemy, dir = PieceBlackPawn, PieceWhitePawn, 1
+	}
+	if (c == ColorWhite && y != 6) || (c == ColorBlack && y != 3) {
+		return false
+	}
+
+	// The enemy pawn that moved two squares must be in front of the
+	// target, and the target square that it skipped must be empty
+	if b.Piece(XY(x, y+dir)) != enemy || b.Piece(target) != PieceNone {
+		return false
+	}
+
+	for _, dx := range []int{-1, 1} {
+		if !onBoard(x+dx, y+dir) {
+			continue
+		}
+		from := XY(x+dx, y+dir)
+		if b.Piece(from) != pawn {
+			continue
+		}
+		nb := b.makeMove(NewMove(from, target, PieceNone, MoveFlagCapture|MoveFlagEnPassant))
+		if !nb.isKingAttacked(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// enPassantPosition returns the en passant target position, if there is one
+func (b *Board) enPassantPosition() (Position, bool) {
+	t := b.getEnPassantTarget()
+	if t == 0 {
+		return 0, false
+	}
+	if t <= 8 {
+		return XY(t, 3), true
+	}
+	return XY(t-8, 6), true
+}
+
+func (b *Board) setEnPassantTarget(i int) {
 	b.extra &= 0b11111111_11111110_00001111_11111111
 	b.extra |= uint32(i) << 12
 }
@@ -343,31 +580,50 @@ func (b *Board) getEnPassantTarget() int {
 	return int((b.extra & 0b00000000_00000001_11110000_00000000) >> 12)
 }
 
+// pieceCount is the number of pieces other than pawns and the king of one color
+type pieceCount struct {
+	queens int
+	rooks  int
+	minors int
+}
+
 // isEndGame returns true when
 //    1. Both sides have no queens or
 //    2. Every side which has a queen has additionally no other pieces or one minor piece maximum.
 func (b *Board) isEndGame() bool {
-	var wQueen, bQueen bool
-	var wCount, bCount int
+	white, black := b.pieceCounts()
+	return (white.queens == 0 || white.minors <= 1) && (black.queens == 0 || black.minors <= 1)
+}
+
+// hasPieces returns true if color c has any pieces other than pawns and
+// the king. Positions without pieces are where zugzwang is common.
+func (b *Board) hasPieces(c Color) bool {
+	white, black := b.pieceCounts()
+	count := white
+	if c == ColorBlack {
+		count = black
+	}
+	return count.queens+count.rooks+count.minors > 0
+}
+
+// pieceCounts returns the number of pieces other than pawns and the king for both colors
+func (b *Board) pieceCounts() (white, black pieceCount) {
 	for i := 0; i < 64; i++ {
-		piece := b.Piece(Pos(i))
-		if piece == PieceWhiteQueen {
-			wQueen = true
-		}
-		if piece == PieceBlackQueen {
-			bQueen = true
-		}
+		switch b.Piece(Pos(i)) {
+		case PieceWhiteQueen:
+			white.queens++
+		case PieceBlackQueen:
+			black.queens++
+		case PieceWhiteRook:
+			white.rooks++
+		case PieceBlackRook:
+			black.rooks++
 		// Bishops and knights counts as minor pieces (https://chessdelta.com/minor-pieces-and-major-pieces-in-chess/)
-		if piece == PieceWhiteBishop || piece == PieceWhiteKnight {
-			wCount++
+		case PieceWhiteBishop, PieceWhiteKnight:
+			white.minors++
+		case PieceBlackBishop, PieceBlackKnight:
+			black.minors++
 		}
-		if piece == PieceBlackBishop || piece == PieceBlackKnight {
-			bCount++
-		}
-	}
-
-	if (!wQueen || wCount <= 1) && (!bQueen || bCount <= 1) {
-		return true
 	}
-	return false
+	return white, black
 }
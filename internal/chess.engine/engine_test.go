@@ -236,7 +236,7 @@ func TestBoard_BlackCastlingRights(t *testing.T) {
 	assert.Equal(t, true, b.CastlingRights(CastlingBlackQueen))
 
 	b = b.MovePiece(Alg("b2"), Alg("b4"))
-	b = b.MovePiece(Alg("a7"), Alg("a5"))
+	b = b.MovePiece(Alg("a7"), Alg("a6"))
 	b = b.MovePiece(Alg("b4"), Alg("b5"))
 	b = b.MovePiece(Alg("a8"), Alg("a7"))
 
@@ -312,17 +312,248 @@ func TestBoard_HalfMoveCount_CaptureReset(t *testing.T) {
 }
 
 func TestBoard_EnPassant(t *testing.T) {
+	// No pawn can capture, so there is no target
 	b := NewBoard(true)
 	b = b.MovePiece(Alg("b2"), Alg("b4"))
-	assert.Equal(t, 1, b.getEnPassantTarget())
-	b = b.MovePiece(Alg("b7"), Alg("b6"))
 	assert.Equal(t, 0, b.getEnPassantTarget())
 
 	b = NewBoard(true)
-	b = b.MovePiece(Alg("b2"), Alg("b3"))
-	assert.Equal(t, 0, b.getEnPassantTarget())
-	b = b.MovePiece(Alg("b7"), Alg("b5"))
-	assert.Equal(t, 9, b.getEnPassantTarget())
+	b = b.MovePiece(Alg("b2"), Alg("b4"))
+	b = b.MovePiece(Alg("g8"), Alg("f6"))
+	b = b.MovePiece(Alg("b4"), Alg("b5"))
+	b = b.MovePiece(Alg("c7"), Alg("c5"))
+	assert.Equal(t, 11, b.getEnPassantTarget())
+	assert.Equal(t, "rnbqkb1r/pp1ppppp/5n2/1Pp5/8/8/P1PPPPPP/RNBQKBNR w KQkq c6 0 3", b.ToFEN())
 	b = b.MovePiece(Alg("c2"), Alg("c3"))
 	assert.Equal(t, 0, b.getEnPassantTarget())
+
+	b = NewBoard(true)
+	b = b.MovePiece(Alg("g1"), Alg("f3"))
+	b = b.MovePiece(Alg("d7"), Alg("d5"))
+	b = b.MovePiece(Alg("f3"), Alg("g1"))
+	b = b.MovePiece(Alg("d5"), Alg("d4"))
+	b = b.MovePiece(Alg("e2"), Alg("e4"))
+	assert.Equal(t, 5, b.getEnPassantTarget())
+	assert.Equal(t, "rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 3", b.ToFEN())
+}
+
+func TestBoard_EnPassantCapture(t *testing.T) {
+	b, err := FromFEN("rnbqkbnr/pp1ppppp/8/1Pp5/8/8/P1PPPPPP/RNBQKBNR w KQkq c6 0 3")
+	assert.Nil(t, err)
+
+	moves := NewMover().GenerateLegalMoves(b)
+	assert.Contains(t, movesToStrings(moves), "b5c6")
+
+	b = b.MovePiece(Alg("b5"), Alg("c6"))
+	assert.Equal(t, PieceWhitePawn, b.Piece(Alg("c6")))
+	assert.Equal(t, PieceNone, b.Piece(Alg("c5")))
+	assert.Equal(t, PieceNone, b.Piece(Alg("b5")))
+	assert.Equal(t, "rnbqkbnr/pp1ppppp/2P5/8/8/8/P1PPPPPP/RNBQKBNR b KQkq - 0 3", b.ToFEN())
+
+	b, err = FromFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 3")
+	assert.Nil(t, err)
+	b = b.MovePiece(Alg("d4"), Alg("e3"))
+	assert.Equal(t, "rnbqkbnr/ppp1pppp/8/8/8/4p3/PPPP1PPP/RNBQKBNR w KQkq - 0 4", b.ToFEN())
+}
+
+func TestBoard_EnPassantPinned(t *testing.T) {
+	// Capturing en passant would expose the king along the rank,
+	// so no target is set
+	b, err := FromFEN("8/8/8/8/k2p3R/8/4P3/4K3 w - - 0 1")
+	assert.Nil(t, err)
+	b = b.MovePiece(Alg("e2"), Alg("e4"))
+	assert.Equal(t, 0, b.getEnPassantTarget())
+	assert.NotContains(t, movesToStrings(NewMover().GenerateLegalMoves(b)), "d4e3")
+}
+
+func TestBoard_FromFENEnPassant(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want string
+	}{
+		{"Capturable", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2"},
+		{"Capturable h-file", "4k3/8/8/8/6Pp/8/8/4K3 b - g3 0 1", "4k3/8/8/8/6Pp/8/8/4K3 b - g3 0 1"},
+		{"Not capturable", "4k3/8/8/3p4/8/8/8/4K3 w - d6 0 2", "4k3/8/8/3p4/8/8/8/4K3 w - - 0 2"},
+		{"Wrong color to move", "4k3/8/8/3pP3/8/8/8/4K3 b - d6 0 2", "4k3/8/8/3pP3/8/8/8/4K3 b - - 0 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, b.ToFEN())
+		})
+	}
+
+	_, err := FromFEN("4k3/8/8/3pP3/8/8/8/4K3 w - d5 0 2")
+	assert.Equal(t, InvalidFEN, err)
+	_, err = FromFEN("4k3/8/8/3pP3/8/8/8/4K3 w - x6 0 2")
+	assert.Equal(t, InvalidFEN, err)
+}
+
+func TestBoard_MakeMove(t *testing.T) {
+	b := NewBoard(true)
+
+	// Flags are taken from the legal move
+	nb, err := b.MakeMove(NewMove(Alg("e2"), Alg("e4"), PieceNone, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, PieceWhitePawn, nb.Piece(Alg("e4")))
+	assert.Equal(t, PieceNone, nb.Piece(Alg("e2")))
+	assert.Equal(t, ColorBlack, nb.ToMove())
+	assert.True(t, nb.Equals(b.MovePiece(Alg("e2"), Alg("e4"))))
+
+	// Illegal moves return an error and leave the board untouched
+	_, err = b.MakeMove(NewMove(Alg("e2"), Alg("e5"), PieceNone, 0))
+	assert.NotNil(t, err)
+	_, err = b.MakeMove(NewMove(Alg("e7"), Alg("e5"), PieceNone, 0))
+	assert.NotNil(t, err)
+	assert.True(t, b.Equals(NewBoard(true)))
+}
+
+func TestBoard_MakeMoveCapture(t *testing.T) {
+	b, err := FromFEN("4k3/8/8/3p4/4Q3/8/8/4K3 w - - 5 20")
+	assert.Nil(t, err)
+
+	nb, err := b.MakeMove(NewMove(Alg("e4"), Alg("d5"), PieceNone, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, PieceWhiteQueen, nb.Piece(Alg("d5")))
+	assert.Equal(t, PieceNone, nb.Piece(Alg("e4")))
+	assert.Equal(t, 0, nb.HalfMoveCount())
+}
+
+func TestBoard_Castling(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		from, to string
+		want     string
+	}{
+		{"White king side", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1", "g1", "r3k2r/8/8/8/8/8/8/R4RK1 b kq - 1 1"},
+		{"White queen side", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1", "c1", "r3k2r/8/8/8/8/8/8/2KR3R b kq - 1 1"},
+		{"Black king side", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8", "g8", "r4rk1/8/8/8/8/8/8/R3K2R w KQ - 1 2"},
+		{"Black queen side", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8", "c8", "2kr3r/8/8/8/8/8/8/R3K2R w KQ - 1 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			nb := b.MovePiece(Alg(tt.from), Alg(tt.to))
+			assert.Equal(t, tt.want, nb.ToFEN())
+		})
+	}
+}
+
+func TestBoard_CastlingNotAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		from, to string
+	}{
+		{"No castling rights", "r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1", "e1", "g1"},
+		{"Path blocked", "r3k2r/8/8/8/8/8/8/RN2K2R w KQkq - 0 1", "e1", "c1"},
+		{"King in check", "r3k2r/8/8/8/8/8/4r3/R3K2R w KQkq - 0 1", "e1", "g1"},
+		{"King passes through check", "r3k2r/8/8/8/8/8/5r2/R3K2R w KQkq - 0 1", "e1", "g1"},
+		{"King ends up in check", "r3k2r/8/8/8/8/8/2r5/R3K2R w KQkq - 0 1", "e1", "c1"},
+		{"Rook missing", "r3k2r/8/8/8/8/8/8/R3K3 w KQkq - 0 1", "e1", "g1"},
+		{"Black path attacked", "r3k2r/8/8/8/8/8/8/R2RK2R b KQkq - 0 1", "e8", "c8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			_, err = b.MakeMove(NewMove(Alg(tt.from), Alg(tt.to), PieceNone, 0))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestBoard_CastlingQueenSideRookAttacked(t *testing.T) {
+	// Only the squares the king passes through must be safe, the
+	// b1 square may be attacked when castling queen side
+	b, err := FromFEN("1r2k3/8/8/8/8/8/8/R3K3 w Q - 0 1")
+	assert.Nil(t, err)
+	nb, err := b.MakeMove(NewMove(Alg("e1"), Alg("c1"), PieceNone, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, "1r2k3/8/8/8/8/8/8/2KR4 b - - 1 1", nb.ToFEN())
+}
+
+func TestBoard_CastlingRightsRookCaptured(t *testing.T) {
+	b, err := FromFEN("r3k2r/8/8/8/8/8/6b1/R3K2R b KQkq - 0 1")
+	assert.Nil(t, err)
+
+	b = b.MovePiece(Alg("g2"), Alg("h1"))
+	assert.Equal(t, false, b.CastlingRights(CastlingWhiteKing))
+	assert.Equal(t, true, b.CastlingRights(CastlingWhiteQueen))
+	assert.Equal(t, true, b.CastlingRights(CastlingBlackKing))
+	assert.Equal(t, true, b.CastlingRights(CastlingBlackQueen))
+
+	b = b.MovePiece(Alg("a1"), Alg("a8"))
+	assert.Equal(t, false, b.CastlingRights(CastlingWhiteKing))
+	assert.Equal(t, false, b.CastlingRights(CastlingWhiteQueen))
+	assert.Equal(t, true, b.CastlingRights(CastlingBlackKing))
+	assert.Equal(t, false, b.CastlingRights(CastlingBlackQueen))
+}
+
+func TestMover_GenerateCastlingMoves(t *testing.T) {
+	b, err := FromFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	assert.Nil(t, err)
+
+	moves := NewMover().GenerateLegalMoves(b)
+	castles := 0
+	for _, m := range moves {
+		if m.IsCastle() {
+			castles++
+		}
+	}
+	assert.Equal(t, 2, castles)
+	assert.Len(t, moves, 26)
+}
+
+func TestBoard_Promotion(t *testing.T) {
+	b, err := FromFEN("1r5k/P7/8/8/8/8/8/7K w - - 0 1")
+	assert.Nil(t, err)
+
+	// MovePiece promotes to a queen
+	nb := b.MovePiece(Alg("a7"), Alg("a8"))
+	assert.Equal(t, PieceWhiteQueen, nb.Piece(Alg("a8")))
+	assert.Equal(t, "Qr5k/8/8/8/8/8/8/7K b - - 0 1", nb.ToFEN())
+
+	// MakeMove promotes to the chosen piece
+	nb, err = b.MakeMove(NewMove(Alg("a7"), Alg("b8"), PieceWhiteKnight, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, PieceWhiteKnight, nb.Piece(Alg("b8")))
+	assert.Equal(t, PieceNone, nb.Piece(Alg("a7")))
+	assert.Equal(t, "1N5k/8/8/8/8/8/8/7K b - - 0 1", nb.ToFEN())
+
+	// Promotions are not allowed before the last rank, or to a king
+	_, err = b.MakeMove(NewMove(Alg("h1"), Alg("h2"), PieceWhiteQueen, 0))
+	assert.NotNil(t, err)
+	_, err = b.MakeMove(NewMove(Alg("a7"), Alg("a8"), PieceWhiteKing, 0))
+	assert.NotNil(t, err)
+
+	// Black promotion
+	b, err = FromFEN("7k/8/8/8/8/8/p7/7K b - - 0 1")
+	assert.Nil(t, err)
+	nb, err = b.MakeMove(NewMove(Alg("a2"), Alg("a1"), PieceBlackRook, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, PieceBlackRook, nb.Piece(Alg("a1")))
+}
+
+func TestBoard_MakeNullMove(t *testing.T) {
+	b, err := FromFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 3")
+	assert.Nil(t, err)
+
+	nb := b.makeNullMove()
+	assert.Equal(t, ColorWhite, nb.ToMove())
+	assert.Equal(t, 0, nb.getEnPassantTarget())
+	assert.Equal(t, nb.computeHash(), nb.Hash())
+	assert.NotEqual(t, b.Hash(), nb.Hash())
+}
+
+func TestBoard_HasPieces(t *testing.T) {
+	b, err := FromFEN("4k3/pppp4/8/8/8/8/PPPP4/4KN2 w - - 0 1")
+	assert.Nil(t, err)
+	assert.True(t, b.hasPieces(ColorWhite))
+	assert.False(t, b.hasPieces(ColorBlack))
+	assert.True(t, NewBoard(true).hasPieces(ColorBlack))
 }
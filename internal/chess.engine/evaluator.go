@@ -1,6 +1,7 @@
 package chess_engine
 
-// Value returns the board value
+// Value returns the board value. The kings are always on the board, so
+// only their position counts, not their piece value.
 func (b *Board) Value() int {
 	value := 0
 
@@ -12,7 +13,10 @@ func (b *Board) Value() int {
 			continue
 		}
 
-		pieceValue := b.getPieceValue(piece)
+		pieceValue := 0
+		if getPieceType(piece) != PieceWhiteKing {
+			pieceValue = b.getPieceValue(piece)
+		}
 		posValue := b.getPiecePositionBonus(pos, piece)
 		// fmt.Printf("%s : val(%d) pos(%d)\n", getPieceName(p), pieceValue, posValue)
 		value += posValue + pieceValue
@@ -85,3 +89,27 @@ func (b *Board) getPiecePositionBonus(pos Position, piece Piece) int {
 
 	return factor * bonusTable[8-y][x-1]
 }
+
+// evaluate returns the board value from the point of view
+// of the color to move, as needed by the search
+func (b *Board) evaluate() int {
+	if b.ToMove() == ColorBlack {
+		return -b.Value()
+	}
+	return b.Value()
+}
+
+// mvvLva returns the most valuable victim - least valuable attacker
+// score of the move m, used to order captures
+func (b *Board) mvvLva(m Move) int {
+	victim := PieceWhitePawn
+	if !m.IsEnPassant() {
+		victim = b.Piece(m.To())
+	}
+
+	score := abs(b.getPieceValue(victim))*10 - abs(b.getPieceValue(b.Piece(m.From())))/10
+	if m.IsPromotion() {
+		score += abs(b.getPieceValue(m.Promotion())) * 10
+	}
+	return score
+}
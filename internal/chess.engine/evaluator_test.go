@@ -74,5 +74,26 @@ func TestEvaluator_ValueKing(t *testing.T) {
 	b := NewBoard(false)
 	b.setPiece(PieceWhiteKing, Alg("e5"))
 	v := b.Value()
-	assert.Equal(t, 20040, v)
+	assert.Equal(t, 40, v)
+}
+
+func TestEvaluator_ValuePromotion(t *testing.T) {
+	b, err := FromFEN("7k/P7/8/8/8/8/8/7K w - - 0 1")
+	assert.Nil(t, err)
+
+	nb, err := b.MakeMove(NewMove(Alg("a7"), Alg("a8"), PieceWhiteQueen, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, b.Value()-150+880, nb.Value())
+}
+
+func TestEvaluator_MvvLva(t *testing.T) {
+	b, err := FromFEN("4k3/8/8/3q4/2P5/5Q2/8/4K3 w - - 0 1")
+	assert.Nil(t, err)
+
+	pawnTakesQueen := b.mvvLva(NewMove(Alg("c4"), Alg("d5"), PieceNone, MoveFlagCapture))
+	queenTakesQueen := b.mvvLva(NewMove(Alg("f3"), Alg("d5"), PieceNone, MoveFlagCapture))
+	queenTakesPawn := b.mvvLva(NewMove(Alg("d5"), Alg("c4"), PieceNone, MoveFlagCapture))
+
+	assert.Greater(t, pawnTakesQueen, queenTakesQueen)
+	assert.Greater(t, queenTakesQueen, queenTakesPawn)
 }
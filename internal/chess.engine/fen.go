@@ -48,6 +48,14 @@ func FromFEN(fen string) (*Board, error) {
 		}
 	}
 
+	// Only keep the en passant target if it can be captured, so that
+	// the board is the same as if the position was reached by moves
+	if !nb.canCaptureEnPassant() {
+		nb.clearEnPassantTarget()
+	}
+
+	nb.hash = nb.computeHash()
+
 	return nb, nil
 }
 
@@ -127,7 +135,7 @@ func (b *Board) toFenEnPassant() string {
 	if t >= 1 && t <= 8 {
 		result = getFileLetter(t) + "3"
 	} else {
-		result = getFileLetter(t) + "6"
+		result = getFileLetter(t-8) + "6"
 	}
 	return result
 }
@@ -239,11 +247,18 @@ func (b *Board) fromFenEnPassant(s string) error {
 		b.clearEnPassantTarget()
 		return nil
 	}
-	i := Alg(s)
-	if i >= 16 && i <= 23 {
-		b.setEnPassantTarget(i - 15)
-	} else {
-		b.setEnPassantTarget(i - 47)
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' {
+		return InvalidFEN
+	}
+
+	x, _ := Alg(s[0:1] + "1").ToXY()
+	switch s[1] {
+	case '3':
+		b.setEnPassantTarget(x)
+	case '6':
+		b.setEnPassantTarget(x + 8)
+	default:
+		return InvalidFEN
 	}
 
 	return nil
@@ -1,6 +1,7 @@
 package chess_engine
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,8 +25,8 @@ func TestBoard_FENAndPrint(t *testing.T) {
 		},
 		{
 			"FEN 3",
-			"r3k2r/pp1n2pp/2p2q2/b2p1n2/BP1Pp3/P1N2P2/2PB2PP/R2Q1RK1 w kq b3 0 13",
-			"r   k  r\npp n  pp\n  p  q  \nb  p n  \nBP Pp   \nP N  P  \n  PB  PP\nR  Q RK \nWhite to move\nCastling : kq\nEn passant : b3\nHalf move count : 0\nMove count : 13\n",
+			"r3k2r/pp1n2pp/2p2q2/b2p1n2/BP1Pp3/P1N1BP2/2P3PP/R2Q1RK1 b kq d3 0 13",
+			"r   k  r\npp n  pp\n  p  q  \nb  p n  \nBP Pp   \nP N BP  \n  P   PP\nR  Q RK \nBlack to move\nCastling : kq\nEn passant : d3\nHalf move count : 0\nMove count : 13\n",
 		},
 	}
 	for _, tt := range tests {
@@ -38,3 +39,25 @@ func TestBoard_FENAndPrint(t *testing.T) {
 		})
 	}
 }
+
+func TestBoard_FENEnPassant(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want string
+	}{
+		{"White captures", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "d6"},
+		{"Black captures", "4k3/8/8/8/3Pp3/8/8/4K3 b - d3 0 1", "d3"},
+		{"No pawn that moved", "4k3/8/8/4P3/8/8/8/4K3 w - d6 0 1", "-"},
+		{"Own pawn that moved", "4k3/8/8/8/3pp3/8/8/4K3 b - d3 0 1", "-"},
+		{"Target not empty", "4k3/8/3n4/3pP3/8/8/8/4K3 w - d6 0 1", "-"},
+		{"No pawn that captures", "4k3/8/8/3p4/8/8/8/4K3 w - d6 0 1", "-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, strings.Fields(b.ToFEN())[3])
+		})
+	}
+}
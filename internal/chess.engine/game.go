@@ -0,0 +1,128 @@
+package chess_engine
+
+import (
+	"errors"
+)
+
+// Game represents a chess game
+//  - boards - boards[0] is the starting board, and boards[i] is the board after i moves
+//  - moves  - moves[i] is the move made on boards[i]
+//  - ply    - the current position in the game, moves after ply can be redone
+type Game struct {
+	boards []*Board
+	moves  []Move
+	ply    int
+}
+
+// NewGame creates a new game starting from the board b
+func NewGame(b *Board) *Game {
+	return &Game{boards: []*Board{b}}
+}
+
+// Start returns the starting board of the game
+func (g *Game) Start() *Board {
+	return g.boards[0]
+}
+
+// Board returns the board at the current ply
+func (g *Game) Board() *Board {
+	return g.boards[g.ply]
+}
+
+// BoardAt returns the board after ply moves
+func (g *Game) BoardAt(ply int) (*Board, error) {
+	if ply < 0 || ply >= len(g.boards) {
+		return nil, errors.New("invalid ply")
+	}
+	return g.boards[ply], nil
+}
+
+// Ply returns the number of moves made to reach the current board
+func (g *Game) Ply() int {
+	return g.ply
+}
+
+// Length returns the number of moves in the game, including moves that can be redone
+func (g *Game) Length() int {
+	return len(g.moves)
+}
+
+// Moves returns the moves of the game, including moves that can be redone
+func (g *Game) Moves() []Move {
+	moves := make([]Move, len(g.moves))
+	copy(moves, g.moves)
+	return moves
+}
+
+// Play makes the move m on the current board. If the move is the move
+// that would be redone, the redo history is kept, otherwise it is discarded.
+func (g *Game) Play(m Move) error {
+	legal, err := g.Board().checkValidMove(m)
+	if err != nil {
+		return err
+	}
+
+	if g.CanRedo() && g.moves[g.ply] == legal {
+		g.ply++
+		return nil
+	}
+
+	g.boards = append(g.boards[:g.ply+1], g.Board().makeMove(legal))
+	g.moves = append(g.moves[:g.ply], legal)
+	g.ply++
+
+	return nil
+}
+
+// CanUndo returns true if there is a move to undo
+func (g *Game) CanUndo() bool {
+	return g.ply > 0
+}
+
+// CanRedo returns true if there is a move to redo
+func (g *Game) CanRedo() bool {
+	return g.ply < len(g.moves)
+}
+
+// Undo takes back the last move, and returns false if there was none
+func (g *Game) Undo() bool {
+	if !g.CanUndo() {
+		return false
+	}
+	g.ply--
+	return true
+}
+
+// Redo makes the last undone move again, and returns false if there was none
+func (g *Game) Redo() bool {
+	if !g.CanRedo() {
+		return false
+	}
+	g.ply++
+	return true
+}
+
+// GoTo jumps to the board after ply moves
+func (g *Game) GoTo(ply int) error {
+	if ply < 0 || ply > len(g.moves) {
+		return errors.New("invalid ply")
+	}
+	g.ply = ply
+	return nil
+}
+
+// Hashes returns the hashes of the boards from the start of the game
+// up to and including the current board
+func (g *Game) Hashes() []uint64 {
+	hashes := make([]uint64, g.ply+1)
+	for i := range hashes {
+		hashes[i] = g.boards[i].Hash()
+	}
+	return hashes
+}
+
+// Status returns the status of the game at the current ply,
+// including threefold repetition
+func (g *Game) Status() GameStatus {
+	return g.Board().StatusWithHistory(g.boards[:g.ply])
+}
@@ -0,0 +1,102 @@
+package chess_engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func playMoves(t *testing.T, g *Game, moves ...string) {
+	for _, m := range moves {
+		err := g.Play(NewMove(Alg(m[0:2]), Alg(m[2:4]), PieceNone, 0))
+		assert.Nil(t, err, "move %s", m)
+	}
+}
+
+func TestGame_Play(t *testing.T) {
+	g := NewGame(NewBoard(true))
+	assert.Equal(t, 0, g.Ply())
+	assert.True(t, g.Board().Equals(NewBoard(true)))
+
+	playMoves(t, g, "e2e4", "e7e5", "g1f3")
+	assert.Equal(t, 3, g.Ply())
+	assert.Equal(t, 3, g.Length())
+	assert.Equal(t, []string{"e2e4", "e7e5", "g1f3"}, []string{g.Moves()[0].String(), g.Moves()[1].String(), g.Moves()[2].String()})
+	assert.True(t, g.Moves()[0].IsDoublePush(), "moves are stored with their flags")
+	assert.Equal(t, "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2", g.Board().ToFEN())
+	assert.True(t, g.Start().Equals(NewBoard(true)))
+
+	err := g.Play(NewMove(Alg("e5"), Alg("e4"), PieceNone, 0))
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, g.Ply())
+}
+
+func TestGame_UndoRedo(t *testing.T) {
+	g := NewGame(NewBoard(true))
+	assert.False(t, g.Undo())
+	assert.False(t, g.Redo())
+
+	playMoves(t, g, "e2e4", "e7e5", "g1f3")
+	after := g.Board()
+
+	assert.True(t, g.Undo())
+	assert.True(t, g.Undo())
+	assert.Equal(t, 1, g.Ply())
+	assert.Equal(t, ColorBlack, g.Board().ToMove())
+	assert.True(t, g.CanRedo())
+
+	assert.True(t, g.Redo())
+	assert.True(t, g.Redo())
+	assert.False(t, g.Redo())
+	assert.True(t, g.Board().Equals(after))
+
+	// Playing the move that would be redone keeps the redo history
+	assert.Nil(t, g.GoTo(1))
+	playMoves(t, g, "e7e5")
+	assert.Equal(t, 3, g.Length())
+	assert.True(t, g.CanRedo())
+
+	// Playing another move discards it
+	assert.True(t, g.Undo())
+	playMoves(t, g, "c7c5")
+	assert.Equal(t, 2, g.Length())
+	assert.False(t, g.CanRedo())
+}
+
+func TestGame_GoTo(t *testing.T) {
+	g := NewGame(NewBoard(true))
+	playMoves(t, g, "e2e4", "e7e5", "g1f3", "b8c6")
+
+	assert.Nil(t, g.GoTo(0))
+	assert.True(t, g.Board().Equals(NewBoard(true)))
+	assert.Nil(t, g.GoTo(4))
+	assert.Equal(t, "r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3", g.Board().ToFEN())
+	assert.Nil(t, g.GoTo(2))
+	assert.Equal(t, "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2", g.Board().ToFEN())
+
+	assert.NotNil(t, g.GoTo(-1))
+	assert.NotNil(t, g.GoTo(5))
+	assert.Equal(t, 2, g.Ply())
+
+	b, err := g.BoardAt(1)
+	assert.Nil(t, err)
+	assert.Equal(t, ColorBlack, b.ToMove())
+	_, err = g.BoardAt(5)
+	assert.NotNil(t, err)
+}
+
+func TestGame_Status(t *testing.T) {
+	g := NewGame(NewBoard(true))
+	playMoves(t, g, "g1f3", "g8f6", "f3g1", "f6g8", "g1f3", "g8f6", "f3g1")
+	assert.Equal(t, StatusOngoing, g.Status())
+	playMoves(t, g, "f6g8")
+	assert.Equal(t, StatusThreefoldRepetition, g.Status())
+
+	// Only the history up to the current ply counts
+	g.Undo()
+	assert.Equal(t, StatusOngoing, g.Status())
+
+	g = NewGame(NewBoard(true))
+	playMoves(t, g, "f2f3", "e7e5", "g2g4", "d8h4")
+	assert.Equal(t, StatusCheckmate, g.Status())
+}
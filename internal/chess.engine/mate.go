@@ -0,0 +1,173 @@
+package chess_engine
+
+import (
+	"context"
+)
+
+// MateResult contains the result of a mate search
+//  - Found     - true if a forced mate was found
+//  - Moves     - the number of moves to mate, by the color to move
+//  - PV        - the mating line, with the longest defence
+//  - Nodes     - the number of positions visited
+//  - Cancelled - true if the search was cancelled before it was finished
+type MateResult struct {
+	Found     bool
+	Moves     int
+	PV        []Move
+	Nodes     uint64
+	Cancelled bool
+}
+
+// mateSearch holds the state of a mate search
+//  - polls - the number of calls to shouldStop, the context is checked every checkInterval calls
+type mateSearch struct {
+	ctx       context.Context
+	mover     *Mover
+	nodes     uint64
+	polls     uint64
+	cancelled bool
+}
+
+// FindMate searches for a forced mate in at most n moves by the color to
+// move. Unlike the normal search, there is no evaluation and no pruning, so
+// a mate that isn't found is proven not to exist (unless the search was
+// cancelled). Mates in 1, 2, 3 and so on are tried in order, so the
+// shortest mate is found. On the last move, only checks are tried.
+func FindMate(ctx context.Context, b *Board, n int) MateResult {
+	s := &mateSearch{ctx: ctx, mover: NewMover()}
+
+	for k := 1; k <= n; k++ {
+		found := s.attack(b, k)
+		if s.cancelled {
+			return MateResult{Nodes: s.nodes, Cancelled: true}
+		}
+		if found {
+			pv := s.pv(b, k)
+			if s.cancelled {
+				return MateResult{Nodes: s.nodes, Cancelled: true}
+			}
+			return MateResult{Found: true, Moves: k, PV: pv, Nodes: s.nodes}
+		}
+	}
+
+	return MateResult{Nodes: s.nodes}
+}
+
+//
+// Private functions
+//
+
+// attack returns true if the color to move can mate in at most n moves
+func (s *mateSearch) attack(b *Board, n int) bool {
+	s.nodes++
+	if s.shouldStop() || b.isDraw() {
+		return false
+	}
+
+	for _, m := range s.attackingMoves(b, n) {
+		if s.defend(b.makeMove(m), n-1) {
+			return true
+		}
+		if s.cancelled {
+			return false
+		}
+	}
+
+	return false
+}
+
+// defend returns true if the color to move is mated within n moves,
+// whatever it does
+func (s *mateSearch) defend(b *Board, n int) bool {
+	s.nodes++
+	moves := s.mover.GenerateLegalMoves(b)
+	if len(moves) == 0 {
+		return b.InCheck()
+	}
+	if n == 0 || b.isDraw() {
+		return false
+	}
+
+	for _, m := range moves {
+		if !s.attack(b.makeMove(m), n) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// attackingMoves returns the legal moves of the attacker with checks first,
+// since they are the most likely to mate. On the last move, where only a
+// check can mate, only checks are returned.
+func (s *mateSearch) attackingMoves(b *Board, n int) []Move {
+	var checks, others []Move
+	for _, m := range s.mover.GenerateLegalMoves(b) {
+		if b.makeMove(m).InCheck() {
+			checks = append(checks, m)
+		} else if n > 1 {
+			others = append(others, m)
+		}
+	}
+
+	return append(checks, others...)
+}
+
+// pv returns the mating line for a mate in n moves, where the defender
+// plays the moves that delay the mate the longest
+func (s *mateSearch) pv(b *Board, n int) []Move {
+	var pv []Move
+	for n > 0 {
+		move := NoMove
+		for _, m := range s.attackingMoves(b, n) {
+			if s.defend(b.makeMove(m), n-1) {
+				move = m
+				break
+			}
+		}
+		if move == NoMove || s.cancelled {
+			return pv
+		}
+		pv = append(pv, move)
+		b = b.makeMove(move)
+
+		// Find the defence that is mated last
+		defence, longest := NoMove, 0
+		for _, m := range s.mover.GenerateLegalMoves(b) {
+			nb := b.makeMove(m)
+			for k := 1; k < n; k++ {
+				if s.attack(nb, k) {
+					if k > longest {
+						defence, longest = m, k
+					}
+					break
+				}
+			}
+		}
+		if defence == NoMove {
+			// Checkmate
+			return pv
+		}
+		pv = append(pv, defence)
+		b = b.makeMove(defence)
+		n = longest
+	}
+
+	return pv
+}
+
+// shouldStop returns true if the search has been cancelled
+func (s *mateSearch) shouldStop() bool {
+	if s.cancelled {
+		return true
+	}
+	s.polls++
+	if s.polls%checkInterval == 0 {
+		select {
+		case <-s.ctx.Done():
+			s.cancelled = true
+		default:
+		}
+	}
+	return s.cancelled
+}
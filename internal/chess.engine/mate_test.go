@@ -0,0 +1,129 @@
+package chess_engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMate_FindMate(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		n     int
+		found bool
+		moves int
+		pv    []string
+	}{
+		{"back rank mate in 1", "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 3, true, 1, []string{"a1a8"}},
+		{"queen mate in 1", "7k/5K2/8/8/8/8/8/6Q1 w - - 0 1", 1, true, 1, nil},
+		{"scholar's mate", "r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", 2, true, 1, []string{"h5f7"}},
+		{"defended back rank", "r5k1/5ppp/8/8/8/8/5PPP/1R4K1 w - - 0 1", 2, false, 0, nil},
+		{"two rooks mate in 2", "6k1/8/8/8/8/8/8/RR4K1 w - - 0 1", 2, true, 2, nil},
+		{"no mate", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", 3, false, 0, nil},
+		{"stalemate is no mate", "7k/8/6QK/8/8/8/8/8 b - - 0 1", 2, false, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+
+			r := FindMate(context.Background(), b, tt.n)
+			assert.Equal(t, tt.found, r.Found)
+			assert.Equal(t, tt.moves, r.Moves)
+			assert.False(t, r.Cancelled)
+			if tt.pv != nil {
+				assert.Equal(t, tt.pv, movesToStrings(r.PV))
+			}
+			if tt.found {
+				assert.Len(t, r.PV, 2*tt.moves-1)
+			}
+		})
+	}
+}
+
+func TestMate_FindMateCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := FindMate(ctx, NewBoard(true), 10)
+	assert.True(t, r.Cancelled)
+	assert.False(t, r.Found)
+}
+
+func TestMate_FindMateTimeout(t *testing.T) {
+	// The search is stopped while it is running
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	r := FindMate(ctx, NewBoard(true), 10)
+	assert.True(t, r.Cancelled)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestMate_MateIn(t *testing.T) {
+	tests := []struct {
+		score int
+		moves int
+		ok    bool
+	}{
+		{scoreMate - 1, 1, true},
+		{scoreMate - 3, 2, true},
+		{-scoreMate + 2, -1, true},
+		{-scoreMate + 4, -2, true},
+		{0, 0, false},
+		{900, 0, false},
+	}
+	for _, tt := range tests {
+		moves, ok := MateIn(tt.score)
+		assert.Equal(t, tt.moves, moves, "%d", tt.score)
+		assert.Equal(t, tt.ok, ok, "%d", tt.score)
+	}
+}
+
+func TestMate_SearchMateDistance(t *testing.T) {
+	// The search scores give exact mate distances
+	b, err := FromFEN("6k1/8/8/8/8/8/8/RR4K1 w - - 0 1")
+	assert.Nil(t, err)
+	r := Search(b, 5)
+	moves, ok := MateIn(r.Score)
+	assert.True(t, ok)
+	assert.Equal(t, 2, moves)
+
+	// The mated side sees the same distance
+	b, err = FromFEN("5k2/1R6/8/8/8/8/8/R5K1 b - - 0 1")
+	assert.Nil(t, err)
+	r = Search(b, 5)
+	moves, ok = MateIn(r.Score)
+	assert.True(t, ok)
+	assert.Equal(t, -1, moves)
+}
+
+func TestMate_ScoresWithoutKingValue(t *testing.T) {
+	// A king alone is not worth 20000
+	b, err := FromFEN("4k3/8/8/8/8/8/8/4K3 w - - 0 1")
+	assert.Nil(t, err)
+	r := Search(b, 3)
+	_, ok := MateIn(r.Score)
+	assert.False(t, ok)
+	assert.Less(t, abs(r.Score), 100)
+}
+
+func TestMate_FindMateTime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping mate in 3 in short mode")
+	}
+
+	// Mate in 3 with a queen sacrifice
+	b, err := FromFEN("r1b3kr/ppp1Bp1p/1b6/n2P4/2p3q1/2Q2N2/P4PPP/RN2R1K1 w - - 1 0")
+	assert.Nil(t, err)
+	start := time.Now()
+	r := FindMate(context.Background(), b, 3)
+	assert.True(t, r.Found)
+	assert.Equal(t, 3, r.Moves)
+	assert.Len(t, r.PV, 5)
+	assert.Less(t, int64(time.Since(start)), int64(time.Minute))
+}
@@ -22,6 +22,39 @@ func getFileLetter(f int) string {
 	return ""
 }
 
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// getPieceType returns the piece without its color, i e
+// the white piece of the same kind
+func getPieceType(p Piece) Piece {
+	return p & 0b0111
+}
+
+// getColoredPiece returns the piece of type p with color c
+func getColoredPiece(p Piece, c Color) Piece {
+	if c == ColorBlack {
+		return getPieceType(p) | 0b1000
+	}
+	return getPieceType(p)
+}
+
+// getOppositeColor returns the color of the opponent
+func getOppositeColor(c Color) Color {
+	switch c {
+	case ColorWhite:
+		return ColorBlack
+	case ColorBlack:
+		return ColorWhite
+	default:
+		return ColorNone
+	}
+}
+
 func getPieceName(p Piece) string {
 	switch p {
 	case PieceNone:
@@ -0,0 +1,118 @@
+package chess_engine
+
+import (
+	"errors"
+	"strings"
+)
+
+// Move represents a chess move
+//  - bit 0-5   - from position
+//  - bit 6-11  - to position
+//  - bit 12-15 - promotion piece (PieceNone if not a promotion)
+//  - bit 16-19 - flags : capture, double push, en passant, castle
+type Move uint32
+
+type MoveFlag uint32
+
+const (
+	MoveFlagCapture MoveFlag = 1 << iota
+	MoveFlagDoublePush
+	MoveFlagEnPassant
+	MoveFlagCastle
+)
+
+// NoMove is the zero move, it is never a valid move since from and to are equal
+const NoMove Move = 0
+
+// NewMove creates a new move
+func NewMove(from, to Position, promotion Piece, flags MoveFlag) Move {
+	return Move(from) | Move(to)<<6 | Move(promotion)<<12 | Move(flags)<<16
+}
+
+// ParseMove parses a move in coordinate notation (i e "e2e4" or "e7e8q").
+// The move has no flags, they are set when the move is made.
+func ParseMove(s string) (Move, error) {
+	if len(s) != 4 && len(s) != 5 {
+		return NoMove, errors.New("invalid move : " + s)
+	}
+	for i := 0; i < 4; i += 2 {
+		if s[i] < 'a' || s[i] > 'h' || s[i+1] < '1' || s[i+1] > '8' {
+			return NoMove, errors.New("invalid move : " + s)
+		}
+	}
+
+	promotion := PieceNone
+	if len(s) == 5 {
+		if !strings.Contains("nbrq", s[4:]) {
+			return NoMove, errors.New("invalid promotion piece : " + s)
+		}
+		promotion = getPieceFromLetter(strings.ToUpper(s[4:]))
+	}
+
+	return NewMove(Alg(s[0:2]), Alg(s[2:4]), promotion, 0), nil
+}
+
+// From returns the position the piece is moved from
+func (m Move) From() Position {
+	return Position(m & 0b111111)
+}
+
+// To returns the position the piece is moved to
+func (m Move) To() Position {
+	return Position(m >> 6 & 0b111111)
+}
+
+// Promotion returns the piece that a pawn is promoted to, or PieceNone
+func (m Move) Promotion() Piece {
+	return Piece(m >> 12 & 0b1111)
+}
+
+// Flags returns the move flags
+func (m Move) Flags() MoveFlag {
+	return MoveFlag(m >> 16 & 0b1111)
+}
+
+// IsCapture returns true if the move captures a piece (including en passant)
+func (m Move) IsCapture() bool {
+	return m.Flags()&MoveFlagCapture != 0
+}
+
+// IsDoublePush returns true if the move is a pawn moving two squares
+func (m Move) IsDoublePush() bool {
+	return m.Flags()&MoveFlagDoublePush != 0
+}
+
+// IsEnPassant returns true if the move is an en passant capture
+func (m Move) IsEnPassant() bool {
+	return m.Flags()&MoveFlagEnPassant != 0
+}
+
+// IsCastle returns true if the move is a castling king move
+func (m Move) IsCastle() bool {
+	return m.Flags()&MoveFlagCastle != 0
+}
+
+// IsPromotion returns true if the move promotes a pawn
+func (m Move) IsPromotion() bool {
+	return m.Promotion() != PieceNone
+}
+
+// IsQuiet returns true if the move neither captures nor promotes
+func (m Move) IsQuiet() bool {
+	return !m.IsCapture() && !m.IsPromotion()
+}
+
+// String returns the move in coordinate notation (i e "e2e4" or "e7e8q")
+func (m Move) String() string {
+	s := m.From().ToAlg() + m.To().ToAlg()
+	if m.IsPromotion() {
+		s += strings.ToLower(getLetterFromPiece(m.Promotion()))
+	}
+	return s
+}
+
+// sameMove returns true if the moves have the same from and to positions
+// and the same kind of promotion piece, flags and colors are not compared
+func (m Move) sameMove(o Move) bool {
+	return m&0xfff == o&0xfff && getPieceType(m.Promotion()) == getPieceType(o.Promotion())
+}
@@ -0,0 +1,80 @@
+package chess_engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMove_FromTo(t *testing.T) {
+	m := NewMove(Alg("g1"), Alg("f3"), PieceNone, 0)
+	assert.Equal(t, Alg("g1"), m.From())
+	assert.Equal(t, Alg("f3"), m.To())
+	assert.Equal(t, PieceNone, m.Promotion())
+	assert.Equal(t, MoveFlag(0), m.Flags())
+	assert.True(t, m.IsQuiet())
+	assert.Equal(t, "g1f3", m.String())
+}
+
+func TestMove_Flags(t *testing.T) {
+	tests := []struct {
+		name       string
+		move       Move
+		capture    bool
+		doublePush bool
+		enPassant  bool
+		castle     bool
+	}{
+		{"Capture", NewMove(Alg("e4"), Alg("d5"), PieceNone, MoveFlagCapture), true, false, false, false},
+		{"Double push", NewMove(Alg("e2"), Alg("e4"), PieceNone, MoveFlagDoublePush), false, true, false, false},
+		{"En passant", NewMove(Alg("e5"), Alg("d6"), PieceNone, MoveFlagCapture|MoveFlagEnPassant), true, false, true, false},
+		{"Castle", NewMove(Alg("e1"), Alg("g1"), PieceNone, MoveFlagCastle), false, false, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.capture, tt.move.IsCapture())
+			assert.Equal(t, tt.doublePush, tt.move.IsDoublePush())
+			assert.Equal(t, tt.enPassant, tt.move.IsEnPassant())
+			assert.Equal(t, tt.castle, tt.move.IsCastle())
+			assert.False(t, tt.move.IsPromotion())
+		})
+	}
+}
+
+func TestMove_Promotion(t *testing.T) {
+	m := NewMove(Alg("b7"), Alg("a8"), PieceWhiteKnight, MoveFlagCapture)
+	assert.Equal(t, Alg("b7"), m.From())
+	assert.Equal(t, Alg("a8"), m.To())
+	assert.Equal(t, PieceWhiteKnight, m.Promotion())
+	assert.True(t, m.IsPromotion())
+	assert.True(t, m.IsCapture())
+	assert.False(t, m.IsQuiet())
+	assert.Equal(t, "b7a8n", m.String())
+}
+
+func TestMove_ParseMove(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Move
+		wantErr bool
+	}{
+		{"e2e4", NewMove(Alg("e2"), Alg("e4"), PieceNone, 0), false},
+		{"e7e8q", NewMove(Alg("e7"), Alg("e8"), PieceWhiteQueen, 0), false},
+		{"a2a1n", NewMove(Alg("a2"), Alg("a1"), PieceWhiteKnight, 0), false},
+		{"e7e8k", NoMove, true},
+		{"e7e8x", NoMove, true},
+		{"e7e8Q", NoMove, true},
+		{"e2e9", NoMove, true},
+		{"i2e4", NoMove, true},
+		{"e2", NoMove, true},
+		{"", NoMove, true},
+	}
+	for _, tt := range tests {
+		m, err := ParseMove(tt.s)
+		assert.Equal(t, tt.wantErr, err != nil, tt.s)
+		assert.Equal(t, tt.want, m, tt.s)
+		if !tt.wantErr {
+			assert.Equal(t, tt.s, m.String())
+		}
+	}
+}
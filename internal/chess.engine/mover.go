@@ -3,6 +3,190 @@ package chess_engine
 type Mover struct {
 }
 
+var (
+	knightDirections = [8][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingDirections   = [8][2]int{{0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}, {-1, 1}}
+	bishopDirections = [4][2]int{{1, 1}, {1, -1}, {-1, -1}, {-1, 1}}
+	rookDirections   = [4][2]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+
+	// promotionPieces are the pieces a pawn can be promoted to, best first
+	promotionPieces = [4]Piece{PieceWhiteQueen, PieceWhiteRook, PieceWhiteBishop, PieceWhiteKnight}
+)
+
 func NewMover() *Mover {
 	return &Mover{}
 }
+
+// GenerateMoves returns all pseudo-legal moves for the color to move,
+// i e moves that follow the movement rules of the pieces, but that
+// might leave the own king in check.
+func (m *Mover) GenerateMoves(b *Board) []Move {
+	moves := make([]Move, 0, 64)
+	c := b.ToMove()
+
+	for i := 0; i < 64; i++ {
+		from := Pos(i)
+		if b.Color(from) != c {
+			continue
+		}
+
+		switch getPieceType(b.Piece(from)) {
+		case PieceWhitePawn:
+			moves = m.appendPawnMoves(moves, b, from)
+		case PieceWhiteKnight:
+			moves = m.appendJumpMoves(moves, b, from, knightDirections[:])
+		case PieceWhiteBishop:
+			moves = m.appendSlidingMoves(moves, b, from, bishopDirections[:])
+		case PieceWhiteRook:
+			moves = m.appendSlidingMoves(moves, b, from, rookDirections[:])
+		case PieceWhiteQueen:
+			moves = m.appendSlidingMoves(moves, b, from, bishopDirections[:])
+			moves = m.appendSlidingMoves(moves, b, from, rookDirections[:])
+		case PieceWhiteKing:
+			moves = m.appendJumpMoves(moves, b, from, kingDirections[:])
+			moves = m.appendCastlingMoves(moves, b)
+		}
+	}
+
+	return moves
+}
+
+// GenerateLegalMoves returns all legal moves for the color to move,
+// i e the pseudo-legal moves that don't leave the own king in check.
+func (m *Mover) GenerateLegalMoves(b *Board) []Move {
+	moves := m.GenerateMoves(b)
+	c := b.ToMove()
+
+	legal := moves[:0]
+	for _, move := range moves {
+		nb := b.makeMove(move)
+		if !nb.isKingAttacked(c) {
+			legal = append(legal, move)
+		}
+	}
+
+	return legal
+}
+
+//
+// Private functions
+//
+
+func (m *Mover) appendPawnMoves(moves []Move, b *Board, from Position) []Move {
+	c := b.Color(from)
+	x, y := from.ToXY()
+
+	// White pawns move up the board, black pawns move down
+	dir, startRank := 1, 2
+	if c == ColorBlack {
+		dir, startRank = -1, 7
+	}
+
+	// Pushes
+	if onBoard(x, y+dir) {
+		to := XY(x, y+dir)
+		if b.Piece(to) == PieceNone {
+			moves = m.appendPawnMove(moves, from, to, c, 0)
+
+			if y == startRank {
+				to = XY(x, y+2*dir)
+				if b.Piece(to) == PieceNone {
+					moves = append(moves, NewMove(from, to, PieceNone, MoveFlagDoublePush))
+				}
+			}
+		}
+	}
+
+	// Captures
+	for _, dx := range []int{-1, 1} {
+		if !onBoard(x+dx, y+dir) {
+			continue
+		}
+		to := XY(x+dx, y+dir)
+		if t := b.Color(to); t != ColorNone && t != c {
+			moves = m.appendPawnMove(moves, from, to, c, MoveFlagCapture)
+		}
+	}
+
+	// En passant
+	if target, ok := b.enPassantPosition(); ok {
+		tx, ty := target.ToXY()
+		if ty == y+dir && (tx == x-1 || tx == x+1) {
+			moves = append(moves, NewMove(from, target, PieceNone, MoveFlagCapture|MoveFlagEnPassant))
+		}
+	}
+
+	return moves
+}
+
+func (m *Mover) appendCastlingMoves(moves []Move, b *Board) []Move {
+	for i := range castlings {
+		c := &castlings[i]
+		if b.canCastle(c) {
+			moves = append(moves, NewMove(c.king, c.kingTo, PieceNone, MoveFlagCastle))
+		}
+	}
+
+	return moves
+}
+
+// appendPawnMove adds a pawn move, or all four promotions if
+// the pawn reaches the last rank
+func (m *Mover) appendPawnMove(moves []Move, from, to Position, c Color, flags MoveFlag) []Move {
+	if _, y := to.ToXY(); y != 1 && y != 8 {
+		return append(moves, NewMove(from, to, PieceNone, flags))
+	}
+
+	for _, p := range promotionPieces {
+		moves = append(moves, NewMove(from, to, getColoredPiece(p, c), flags))
+	}
+
+	return moves
+}
+
+// appendJumpMoves adds the moves for pieces that move a single
+// step in each direction (knights and kings)
+func (m *Mover) appendJumpMoves(moves []Move, b *Board, from Position, directions [][2]int) []Move {
+	c := b.Color(from)
+	x, y := from.ToXY()
+
+	for _, d := range directions {
+		if !onBoard(x+d[0], y+d[1]) {
+			continue
+		}
+		to := XY(x+d[0], y+d[1])
+		switch b.Color(to) {
+		case ColorNone:
+			moves = append(moves, NewMove(from, to, PieceNone, 0))
+		case c:
+		default:
+			moves = append(moves, NewMove(from, to, PieceNone, MoveFlagCapture))
+		}
+	}
+
+	return moves
+}
+
+// appendSlidingMoves adds the moves for pieces that slide along rays
+// until they are blocked (bishops, rooks and queens)
+func (m *Mover) appendSlidingMoves(moves []Move, b *Board, from Position, directions [][2]int) []Move {
+	c := b.Color(from)
+	x, y := from.ToXY()
+
+	for _, d := range directions {
+		for tx, ty := x+d[0], y+d[1]; onBoard(tx, ty); tx, ty = tx+d[0], ty+d[1] {
+			to := XY(tx, ty)
+			t := b.Color(to)
+			if t == ColorNone {
+				moves = append(moves, NewMove(from, to, PieceNone, 0))
+				continue
+			}
+			if t != c {
+				moves = append(moves, NewMove(from, to, PieceNone, MoveFlagCapture))
+			}
+			break
+		}
+	}
+
+	return moves
+}
@@ -0,0 +1,107 @@
+package chess_engine
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func movesToStrings(moves []Move) []string {
+	result := make([]string, len(moves))
+	for i, m := range moves {
+		result[i] = m.String()
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestMover_GenerateMovesInitialPosition(t *testing.T) {
+	m := NewMover()
+
+	b := NewBoard(true)
+	assert.Len(t, m.GenerateMoves(b), 20)
+
+	b = b.MovePiece(Alg("e2"), Alg("e4"))
+	assert.Len(t, m.GenerateMoves(b), 20)
+
+	b = b.MovePiece(Alg("e7"), Alg("e5"))
+	assert.Len(t, m.GenerateMoves(b), 29)
+}
+
+func TestMover_GenerateMoves(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want []string
+	}{
+		{
+			"Knight in the corner",
+			"8/8/8/8/8/8/8/N7 w - - 0 1",
+			[]string{"a1b3", "a1c2"},
+		},
+		{
+			"King in the middle",
+			"8/8/8/3p4/4K3/5P2/8/8 w - - 0 1",
+			[]string{"e4d3", "e4d4", "e4d5", "e4e3", "e4e5", "e4f4", "e4f5", "f3f4"},
+		},
+		{
+			"Rook blocked by own and enemy pieces",
+			"8/8/8/8/1p6/8/1R1P4/8 w - - 0 1",
+			[]string{"b2a2", "b2b1", "b2b3", "b2b4", "b2c2", "d2d3", "d2d4"},
+		},
+		{
+			"Bishop rays",
+			"8/8/8/8/8/2p5/1B6/8 w - - 0 1",
+			[]string{"b2a1", "b2a3", "b2c1", "b2c3"},
+		},
+		{
+			"Queen rays",
+			"8/8/8/8/8/pp6/Qp6/1p6 w - - 0 1",
+			[]string{"a2a1", "a2a3", "a2b1", "a2b2", "a2b3"},
+		},
+		{
+			"Blocked pawns and captures",
+			"8/8/8/8/8/p1n5/Pp1P4/8 w - - 0 1",
+			[]string{"d2c3", "d2d3", "d2d4"},
+		},
+		{
+			"Black pawns",
+			"8/3p4/2P5/3p4/3P4/8/8/8 b - - 0 1",
+			[]string{"d7c6", "d7d6"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, movesToStrings(NewMover().GenerateMoves(b)))
+		})
+	}
+}
+
+func TestMover_GeneratePromotions(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want []string
+	}{
+		{
+			"White push and capture",
+			"1r5k/P7/8/8/8/8/8/7K w - - 0 1",
+			[]string{"a7a8b", "a7a8n", "a7a8q", "a7a8r", "a7b8b", "a7b8n", "a7b8q", "a7b8r", "h1g1", "h1g2", "h1h2"},
+		},
+		{
+			"Black push",
+			"7k/8/8/8/8/8/p7/7K b - - 0 1",
+			[]string{"a2a1b", "a2a1n", "a2a1q", "a2a1r", "h8g7", "h8g8", "h8h7"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, movesToStrings(NewMover().GenerateLegalMoves(b)))
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package chess_engine
+
+// Move ordering, alpha-beta cuts off more of the tree when
+// the best moves are searched first :
+// https://www.chessprogramming.org/Move_Ordering
+//
+//  1. The hash move, the best move found by an earlier search of the position
+//  2. Captures and promotions, most valuable victim - least valuable attacker
+//  3. Killer moves, quiet moves that caused a cutoff at the same ply
+//  4. Other quiet moves, by how often they have caused cutoffs (history heuristic)
+const (
+	orderHashMove = 1 << 30
+	orderCapture  = 1 << 24
+	orderKiller   = 1 << 22
+)
+
+// historyMax is the largest history score, all scores are halved when
+// it is reached, so that recent cutoffs weigh more than old ones
+const historyMax = orderKiller - 1
+
+// orderMoves returns the order scores of the moves, use pickMove to get
+// the moves in order
+func (w *searchWorker) orderMoves(b *Board, moves []Move, ply int, hashMove Move) []int {
+	scores := make([]int, len(moves))
+	c := 0
+	if b.ToMove() == ColorBlack {
+		c = 1
+	}
+
+	for i, m := range moves {
+		switch {
+		case w.config.HashMove && m == hashMove:
+			scores[i] = orderHashMove
+		case !m.IsQuiet():
+			if w.config.MVVLVA {
+				scores[i] = orderCapture + b.mvvLva(m)
+			} else {
+				scores[i] = orderCapture
+			}
+		case w.config.KillerMoves && m == w.killers[ply][0]:
+			scores[i] = orderKiller + 1
+		case w.config.KillerMoves && m == w.killers[ply][1]:
+			scores[i] = orderKiller
+		case w.config.HistoryHeuristic:
+			scores[i] = w.history[c][m.From()][m.To()]
+		}
+	}
+
+	return scores
+}
+
+// pickMove moves the move with the highest score, from index i and on,
+// to index i and returns it. Sorting lazily is faster than sorting all
+// moves, since a cutoff often happens after a few moves.
+func pickMove(moves []Move, scores []int, i int) Move {
+	best := i
+	for j := i + 1; j < len(moves); j++ {
+		if scores[j] > scores[best] {
+			best = j
+		}
+	}
+	moves[i], moves[best] = moves[best], moves[i]
+	scores[i], scores[best] = scores[best], scores[i]
+
+	return moves[i]
+}
+
+// updateCutoff updates the killer moves and the history scores when the
+// quiet move m caused a beta cutoff at ply, searched depth plies deep
+func (w *searchWorker) updateCutoff(b *Board, m Move, ply, depth int) {
+	if !m.IsQuiet() {
+		return
+	}
+
+	if w.killers[ply][0] != m {
+		w.killers[ply][1] = w.killers[ply][0]
+		w.killers[ply][0] = m
+	}
+
+	c := 0
+	if b.ToMove() == ColorBlack {
+		c = 1
+	}
+	h := &w.history[c][m.From()][m.To()]
+	*h += depth * depth
+	if *h >= historyMax {
+		w.ageHistory()
+	}
+}
+
+// ageHistory halves all history scores
+func (w *searchWorker) ageHistory() {
+	for c := range w.history {
+		for from := range w.history[c] {
+			for to := range w.history[c][from] {
+				w.history[c][from][to] /= 2
+			}
+		}
+	}
+}
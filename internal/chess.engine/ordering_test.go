@@ -0,0 +1,148 @@
+package chess_engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrdering_OrderMoves(t *testing.T) {
+	b, err := FromFEN("4k3/8/8/3q4/2P1p3/5Q2/8/4K3 w - - 0 1")
+	assert.Nil(t, err)
+
+	w := newSearchWorker(DefaultSearchConfig(), NewTranspositionTable(1))
+	hashMove := NewMove(Alg("f3"), Alg("f7"), PieceNone, 0)
+	killer := NewMove(Alg("e1"), Alg("f2"), PieceNone, 0)
+	w.killers[0][0] = killer
+	w.history[0][Alg("f3")][Alg("g3")] = 100
+
+	moves := w.mover.GenerateLegalMoves(b)
+	scores := w.orderMoves(b, moves, 0, hashMove)
+	var ordered []string
+	for i := range moves {
+		ordered = append(ordered, pickMove(moves, scores, i).String())
+	}
+
+	assert.Equal(t, []string{"f3f7", "c4d5", "f3e4", "e1f2", "f3g3"}, ordered[:5])
+}
+
+func TestOrdering_OrderMovesDisabled(t *testing.T) {
+	b := NewBoard(true)
+	w := newSearchWorker(SearchConfig{}, NewTranspositionTable(1))
+	w.killers[0][0] = NewMove(Alg("g1"), Alg("f3"), PieceNone, 0)
+	w.history[0][Alg("e2")][Alg("e4")] = 100
+
+	moves := w.mover.GenerateLegalMoves(b)
+	for _, score := range w.orderMoves(b, moves, 0, moves[0]) {
+		assert.Equal(t, 0, score)
+	}
+}
+
+func TestOrdering_UpdateCutoff(t *testing.T) {
+	b := NewBoard(true)
+	w := newSearchWorker(DefaultSearchConfig(), NewTranspositionTable(1))
+	m1 := NewMove(Alg("g1"), Alg("f3"), PieceNone, 0)
+	m2 := NewMove(Alg("e2"), Alg("e4"), PieceNone, MoveFlagDoublePush)
+
+	w.updateCutoff(b, m1, 2, 3)
+	w.updateCutoff(b, m2, 2, 3)
+	w.updateCutoff(b, m2, 2, 3)
+	assert.Equal(t, [2]Move{m2, m1}, w.killers[2])
+	assert.Equal(t, 9, w.history[0][Alg("g1")][Alg("f3")])
+	assert.Equal(t, 18, w.history[0][Alg("e2")][Alg("e4")])
+
+	// Captures are not killers
+	c := NewMove(Alg("e2"), Alg("e7"), PieceNone, MoveFlagCapture)
+	w.updateCutoff(b, c, 2, 3)
+	assert.Equal(t, [2]Move{m2, m1}, w.killers[2])
+}
+
+func TestOrdering_AgeHistory(t *testing.T) {
+	b := NewBoard(true)
+	w := newSearchWorker(DefaultSearchConfig(), NewTranspositionTable(1))
+	w.history[1][Alg("a7")][Alg("a6")] = 10
+	w.history[0][Alg("g1")][Alg("f3")] = historyMax - 1
+
+	w.updateCutoff(b, NewMove(Alg("g1"), Alg("f3"), PieceNone, 0), 0, 1)
+	assert.Equal(t, historyMax/2, w.history[0][Alg("g1")][Alg("f3")])
+	assert.Equal(t, 5, w.history[1][Alg("a7")][Alg("a6")])
+}
+
+// benchmarkSearch searches the benchmark positions with the configuration, the
+// number of nodes per search shows the effect of the configuration
+func benchmarkSearch(b *testing.B, config SearchConfig) {
+	positions := []struct {
+		fen   string
+		depth int
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 5},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 4},
+		{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 6},
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", 4},
+	}
+
+	nodes := uint64(0)
+	for i := 0; i < b.N; i++ {
+		for _, p := range positions {
+			board, err := FromFEN(p.fen)
+			if err != nil {
+				b.Fatal(err)
+			}
+			s := NewSearcher()
+			s.Config = config
+			nodes += s.Think(context.Background(), NewGame(board), SearchLimits{Depth: p.depth}, nil).Nodes
+		}
+	}
+	b.ReportMetric(float64(nodes)/float64(b.N), "nodes/op")
+}
+
+func BenchmarkSearch_NoOrdering(b *testing.B) {
+	benchmarkSearch(b, SearchConfig{})
+}
+
+func BenchmarkSearch_HashMove(b *testing.B) {
+	benchmarkSearch(b, SearchConfig{HashMove: true})
+}
+
+func BenchmarkSearch_MVVLVA(b *testing.B) {
+	benchmarkSearch(b, SearchConfig{HashMove: true, MVVLVA: true})
+}
+
+func BenchmarkSearch_KillerMoves(b *testing.B) {
+	benchmarkSearch(b, SearchConfig{HashMove: true, MVVLVA: true, KillerMoves: true})
+}
+
+func BenchmarkSearch_Default(b *testing.B) {
+	benchmarkSearch(b, DefaultSearchConfig())
+}
+
+func BenchmarkSearch_NoSelectiveSearch(b *testing.B) {
+	benchmarkSearch(b, SearchConfig{HashMove: true, MVVLVA: true, KillerMoves: true, HistoryHeuristic: true})
+}
+
+func BenchmarkSearch_NoNullMove(b *testing.B) {
+	config := DefaultSearchConfig()
+	config.NullMove = false
+	benchmarkSearch(b, config)
+}
+
+func BenchmarkSearch_NoLateMoveReductions(b *testing.B) {
+	config := DefaultSearchConfig()
+	config.LateMoveReductions = false
+	benchmarkSearch(b, config)
+}
+
+func BenchmarkSearch_NoFutility(b *testing.B) {
+	config := DefaultSearchConfig()
+	config.ReverseFutility = false
+	config.Futility = false
+	benchmarkSearch(b, config)
+}
+
+func BenchmarkSearch_NoPVS(b *testing.B) {
+	config := DefaultSearchConfig()
+	config.PVS = false
+	config.AspirationWindows = false
+	benchmarkSearch(b, config)
+}
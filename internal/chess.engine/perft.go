@@ -0,0 +1,37 @@
+package chess_engine
+
+// Perft walks the legal move tree to the given depth and returns the
+// number of leaf nodes, used to verify the move generator :
+// https://www.chessprogramming.org/Perft
+func Perft(b *Board, depth int) uint64 {
+	if depth <= 0 {
+		return 1
+	}
+
+	moves := NewMover().GenerateLegalMoves(b)
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+
+	var nodes uint64
+	for _, m := range moves {
+		nodes += Perft(b.makeMove(m), depth-1)
+	}
+
+	return nodes
+}
+
+// PerftDivide returns the perft node count below each legal move,
+// which makes it easy to find the move where a move generator fails
+func PerftDivide(b *Board, depth int) map[Move]uint64 {
+	result := make(map[Move]uint64)
+	if depth <= 0 {
+		return result
+	}
+
+	for _, m := range NewMover().GenerateLegalMoves(b) {
+		result[m] = Perft(b.makeMove(m), depth-1)
+	}
+
+	return result
+}
@@ -0,0 +1,76 @@
+package chess_engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Positions and node counts from https://www.chessprogramming.org/Perft_Results
+func TestPerft(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		nodes []uint64
+	}{
+		{
+			"Initial position",
+			"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			[]uint64{20, 400, 8902, 197281},
+		},
+		{
+			"Kiwipete",
+			"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+			[]uint64{48, 2039, 97862, 4085603},
+		},
+		{
+			"Position 3",
+			"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+			[]uint64{14, 191, 2812, 43238, 674624},
+		},
+		{
+			"Position 4",
+			"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+			[]uint64{6, 264, 9467, 422333},
+		},
+		{
+			"Position 4 mirrored",
+			"r2q1rk1/pP1p2pp/Q4n2/bbp1p3/Np6/1B3NBn/pPPP1PPP/R3K2R b KQ - 0 1",
+			[]uint64{6, 264, 9467, 422333},
+		},
+		{
+			"Position 5",
+			"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+			[]uint64{44, 1486, 62379, 2103487},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			for i, want := range tt.nodes {
+				depth := i + 1
+				if testing.Short() && depth > 3 {
+					break
+				}
+				assert.Equal(t, want, Perft(b, depth), "depth %d", depth)
+			}
+		})
+	}
+}
+
+func TestPerftDivide(t *testing.T) {
+	b := NewBoard(true)
+
+	divide := PerftDivide(b, 3)
+	assert.Len(t, divide, 20)
+
+	var total uint64
+	for m, nodes := range divide {
+		total += nodes
+		if m.String() == "e2e4" {
+			assert.Equal(t, uint64(600), nodes)
+		}
+	}
+	assert.Equal(t, uint64(8902), total)
+}
@@ -0,0 +1,853 @@
+package chess_engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PGNGame is a game in Portable Game Notation :
+// http://www.saremba.de/chessgml/standards/pgn/pgn-complete.htm
+//  - Tags     - the tag pairs in the order they were read, the seven tag roster first
+//  - Comments - comments before the first move
+//  - Moves    - the main line
+//  - Result   - the game termination marker, "1-0", "0-1", "1/2-1/2" or "*"
+//  - Game     - the main line replayed from the start position, or from the FEN tag
+type PGNGame struct {
+	Tags     []PGNTag
+	Comments []string
+	Moves    []PGNMove
+	Result   string
+	Game     *Game
+}
+
+// PGNTag is a tag pair, i e [Event "Casual game"]
+type PGNTag struct {
+	Name  string
+	Value string
+}
+
+// PGNMove is a move with its annotations
+//  - SAN         - the move as it was written
+//  - NAGs        - numeric annotation glyphs, "!" and "?" are read as $1 and $2 and so on
+//  - PreComments - comments before the move, at the start of a variation
+//  - Comments    - comments after the move
+//  - Variations  - alternatives to the move, each starting from the board before it
+type PGNMove struct {
+	Move        Move
+	SAN         string
+	NAGs        []int
+	PreComments []string
+	Comments    []string
+	Variations  [][]PGNMove
+}
+
+// PGNError is an error in the PGN input, at the given line and column (starting at 1)
+type PGNError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+// PGNReader reads games from PGN input, one at a time
+//  - line, column         - the position of the last rune read
+//  - prevLine, prevColumn - the position before it, restored when the rune is unread
+type PGNReader struct {
+	r          *bufio.Reader
+	line       int
+	column     int
+	prevLine   int
+	prevColumn int
+	peeked     *pgnToken
+	failed     bool
+}
+
+// SevenTagRoster contains the tags that every PGN game should have, in order
+var SevenTagRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// pgnToken is a token in the PGN input
+type pgnToken struct {
+	kind   pgnTokenKind
+	value  string
+	line   int
+	column int
+}
+
+type pgnTokenKind int
+
+const (
+	pgnTokenEOF pgnTokenKind = iota
+	pgnTokenSymbol
+	pgnTokenString
+	pgnTokenInteger
+	pgnTokenComment
+	pgnTokenNAG
+	pgnTokenPeriod
+	pgnTokenLeftBracket
+	pgnTokenRightBracket
+	pgnTokenLeftParenthesis
+	pgnTokenRightParenthesis
+)
+
+// pgnWriter writes movetext, with lines wrapped at pgnLineLength columns
+//  - lines - the finished lines
+//  - line  - the line being written
+//  - open  - true after a "(", so that the next token follows it without a space
+type pgnWriter struct {
+	lines []string
+	line  string
+	open  bool
+}
+
+const pgnLineLength = 80
+
+// suffixNAGs are the move suffix annotations and their NAGs
+var suffixNAGs = map[string]int{"!": 1, "?": 2, "!!": 3, "??": 4, "!?": 5, "?!": 6}
+
+// NewPGNReader creates a reader that reads games from r
+func NewPGNReader(r io.Reader) *PGNReader {
+	return &PGNReader{r: bufio.NewReader(r), line: 1}
+}
+
+// NewPGNGame creates a PGN game from the moves of g, with the seven tag roster
+// set to unknown values and the result taken from the status of the game
+func NewPGNGame(g *Game) *PGNGame {
+	p := &PGNGame{Game: g, Result: "*"}
+	for _, name := range SevenTagRoster {
+		p.Tags = append(p.Tags, PGNTag{Name: name, Value: unknownTagValue(name)})
+	}
+	for _, m := range g.Moves() {
+		p.Moves = append(p.Moves, PGNMove{Move: m})
+	}
+
+	var history []*Board
+	for ply := 0; ply < g.Length(); ply++ {
+		b, _ := g.BoardAt(ply)
+		history = append(history, b)
+	}
+	last, _ := g.BoardAt(g.Length())
+	switch status := last.StatusWithHistory(history); {
+	case status.IsDraw():
+		p.Result = "1/2-1/2"
+	case status == StatusCheckmate && last.ToMove() == ColorBlack:
+		p.Result = "1-0"
+	case status == StatusCheckmate:
+		p.Result = "0-1"
+	}
+	p.SetTag("Result", p.Result)
+
+	return p
+}
+
+// ReadPGN reads all games from r
+func ReadPGN(r io.Reader) ([]*PGNGame, error) {
+	var games []*PGNGame
+	reader := NewPGNReader(r)
+	for {
+		g, err := reader.Read()
+		if err == io.EOF {
+			return games, nil
+		}
+		if err != nil {
+			return games, err
+		}
+		games = append(games, g)
+	}
+}
+
+// Read reads the next game, and returns io.EOF when there are no more games.
+// After an error, reading continues with the next game.
+func (r *PGNReader) Read() (*PGNGame, error) {
+	if r.failed {
+		if err := r.skipGame(); err != nil {
+			return nil, err
+		}
+		r.failed = false
+	}
+
+	g, err := r.readGame()
+	if err != nil && err != io.EOF {
+		r.failed = true
+	}
+	return g, err
+}
+
+// Tag returns the value of the tag with the given name, or "" if there is none
+func (g *PGNGame) Tag(name string) string {
+	for _, t := range g.Tags {
+		if t.Name == name {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+// SetTag sets the value of the tag with the given name, the tag is added if there is none
+func (g *PGNGame) SetTag(name, value string) {
+	for i := range g.Tags {
+		if g.Tags[i].Name == name {
+			g.Tags[i].Value = value
+			return
+		}
+	}
+	g.Tags = append(g.Tags, PGNTag{Name: name, Value: value})
+}
+
+// WritePGN writes the games to w, separated by empty lines
+func WritePGN(w io.Writer, games []*PGNGame) error {
+	for i, g := range games {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := g.Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write writes the game in export format : the seven tag roster, the
+// FEN and SetUp tags if the game doesn't start from the start position,
+// the other tags, and the movetext with lines wrapped at 80 columns
+func (g *PGNGame) Write(w io.Writer) error {
+	b, err := g.startBoard()
+	if err != nil {
+		return err
+	}
+	result := g.Result
+	if result == "" {
+		result = "*"
+	}
+
+	var sb strings.Builder
+
+	// Tags
+	for _, name := range SevenTagRoster {
+		value := g.Tag(name)
+		switch {
+		case name == "Result":
+			value = result
+		case value == "":
+			value = unknownTagValue(name)
+		}
+		writeTag(&sb, name, value)
+	}
+	if fen := b.ToFEN(); fen != NewBoard(true).ToFEN() {
+		writeTag(&sb, "SetUp", "1")
+		writeTag(&sb, "FEN", fen)
+	}
+	for _, t := range g.Tags {
+		if !isSevenTagRoster(t.Name) && t.Name != "FEN" && t.Name != "SetUp" {
+			writeTag(&sb, t.Name, t.Value)
+		}
+	}
+	sb.WriteString("\n")
+
+	// Movetext
+	mw := &pgnWriter{}
+	for _, c := range g.Comments {
+		mw.writeComment(c)
+	}
+	if err := mw.writeMoves(b, g.Moves, len(g.Comments) > 0); err != nil {
+		return err
+	}
+	mw.write(result)
+	sb.WriteString(mw.String())
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// String returns the game in export format
+func (g *PGNGame) String() string {
+	var sb strings.Builder
+	if err := g.Write(&sb); err != nil {
+		return err.Error()
+	}
+	return sb.String()
+}
+
+func (e *PGNError) Error() string {
+	return fmt.Sprintf("line %d, column %d : %s", e.Line, e.Column, e.Msg)
+}
+
+//
+// Private functions
+//
+
+func (r *PGNReader) readGame() (*PGNGame, error) {
+	g := &PGNGame{}
+	var fenTag pgnToken
+
+	// Tag pairs
+	for {
+		t, err := r.peek()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == pgnTokenEOF {
+			return nil, io.EOF
+		}
+		if t.kind != pgnTokenLeftBracket {
+			break
+		}
+		tag, err := r.readTag()
+		if err != nil {
+			return nil, err
+		}
+		if tag.Name == "FEN" {
+			fenTag = t
+		}
+		g.Tags = append(g.Tags, tag)
+	}
+	g.Tags = sortTags(g.Tags)
+
+	// The start position
+	b := NewBoard(true)
+	if fen := g.Tag("FEN"); fen != "" {
+		var err error
+		if b, err = FromFEN(fen); err != nil {
+			return nil, fenTag.errorf("invalid FEN tag : %s", fen)
+		}
+	}
+	g.Game = NewGame(b)
+
+	// Movetext, the main line is played in the game
+	moves, comments, err := r.readMoves(b, g.Game, 0)
+	if err != nil {
+		return nil, err
+	}
+	g.Moves, g.Comments = moves, comments
+
+	t, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case t.kind == pgnTokenSymbol && isResult(t.value):
+		g.Result = t.value
+	case t.kind == pgnTokenEOF || t.kind == pgnTokenLeftBracket:
+		// A missing result is accepted at the end of the input, or
+		// before the next game
+		r.peeked = &t
+		g.Result = "*"
+	default:
+		return nil, t.errorf("unexpected %q", t.value)
+	}
+
+	return g, nil
+}
+
+func (r *PGNReader) readTag() (PGNTag, error) {
+	if _, err := r.next(); err != nil {
+		return PGNTag{}, err
+	}
+
+	name, err := r.next()
+	if err != nil {
+		return PGNTag{}, err
+	}
+	if name.kind != pgnTokenSymbol {
+		return PGNTag{}, r.unexpected(name, "expected a tag name")
+	}
+	value, err := r.next()
+	if err != nil {
+		return PGNTag{}, err
+	}
+	if value.kind != pgnTokenString {
+		return PGNTag{}, r.unexpected(value, "expected a tag value")
+	}
+	end, err := r.next()
+	if err != nil {
+		return PGNTag{}, err
+	}
+	if end.kind != pgnTokenRightBracket {
+		return PGNTag{}, r.unexpected(end, "expected ]")
+	}
+
+	return PGNTag{Name: name.value, Value: value.value}, nil
+}
+
+// readMoves reads a line of moves, played from the board b, until the
+// result, the end of a variation or the end of the input. The moves of
+// the main line (depth 0) are played in the game g. Comments before
+// the first move are returned separately.
+func (r *PGNReader) readMoves(b *Board, g *Game, depth int) ([]PGNMove, []string, error) {
+	var moves []PGNMove
+	var comments []string
+	var before *Board // the board before the last move, where variations start
+
+	for {
+		t, err := r.next()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch t.kind {
+		case pgnTokenInteger, pgnTokenPeriod:
+			// Move numbers
+		case pgnTokenComment:
+			if len(moves) == 0 {
+				comments = append(comments, t.value)
+			} else {
+				last := &moves[len(moves)-1]
+				last.Comments = append(last.Comments, t.value)
+			}
+		case pgnTokenNAG:
+			if len(moves) == 0 {
+				return nil, nil, t.errorf("NAG before the first move")
+			}
+			n, err := strconv.Atoi(t.value)
+			if err != nil || n > 255 {
+				return nil, nil, t.errorf("invalid NAG $%s", t.value)
+			}
+			last := &moves[len(moves)-1]
+			last.NAGs = append(last.NAGs, n)
+		case pgnTokenLeftParenthesis:
+			if len(moves) == 0 {
+				return nil, nil, t.errorf("variation before the first move")
+			}
+			variation, comments, err := r.readMoves(before, nil, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(variation) == 0 {
+				return nil, nil, t.errorf("empty variation")
+			}
+			variation[0].PreComments = comments
+			last := &moves[len(moves)-1]
+			last.Variations = append(last.Variations, variation)
+		case pgnTokenRightParenthesis:
+			if depth == 0 {
+				return nil, nil, t.errorf("unexpected )")
+			}
+			return moves, comments, nil
+		case pgnTokenSymbol:
+			if isResult(t.value) {
+				if depth > 0 {
+					return nil, nil, t.errorf("result in a variation")
+				}
+				r.peeked = &t
+				return moves, comments, nil
+			}
+
+			m, err := r.readMove(b, t)
+			if err != nil {
+				return nil, nil, err
+			}
+			if g != nil {
+				_ = g.Play(m.Move)
+			}
+			before, b = b, b.makeMove(m.Move)
+			moves = append(moves, m)
+		case pgnTokenEOF, pgnTokenLeftBracket:
+			if depth > 0 {
+				return nil, nil, r.unexpected(t, "unterminated variation")
+			}
+			r.peeked = &t
+			return moves, comments, nil
+		default:
+			return nil, nil, t.errorf("unexpected %q", t.value)
+		}
+	}
+}
+
+// readMove parses the move in the token t, with any suffix annotation
+func (r *PGNReader) readMove(b *Board, t pgnToken) (PGNMove, error) {
+	san, suffix := t.value, ""
+	if i := strings.IndexAny(san, "!?"); i >= 0 {
+		san, suffix = san[:i], san[i:]
+	}
+
+	m, err := b.ParseSAN(san)
+	if err != nil {
+		return PGNMove{}, t.errorf("%s", err.Error())
+	}
+
+	move := PGNMove{Move: m, SAN: san}
+	if suffix != "" {
+		n, ok := suffixNAGs[suffix]
+		if !ok {
+			return PGNMove{}, t.errorf("invalid annotation %q", suffix)
+		}
+		move.NAGs = append(move.NAGs, n)
+	}
+
+	return move, nil
+}
+
+// skipGame skips the rest of a game after an error, i e everything
+// up to the next tag at the start of a line
+func (r *PGNReader) skipGame() error {
+	if t := r.peeked; t != nil && t.kind == pgnTokenLeftBracket && t.column == 1 {
+		return nil
+	}
+	r.peeked = nil
+	for {
+		c, err := r.readRune()
+		if err == io.EOF {
+			return io.EOF
+		}
+		if err != nil {
+			return err
+		}
+		if c == '[' && r.column == 1 {
+			r.unreadRune()
+			return nil
+		}
+	}
+}
+
+// unexpected returns an error for the token t. A [ is kept, so that reading
+// can continue with the game it starts.
+func (r *PGNReader) unexpected(t pgnToken, msg string) error {
+	if t.kind == pgnTokenLeftBracket {
+		r.peeked = &t
+	}
+	return t.errorf("%s", msg)
+}
+
+func (r *PGNReader) peek() (pgnToken, error) {
+	if r.peeked == nil {
+		t, err := r.scan()
+		if err != nil {
+			return t, err
+		}
+		r.peeked = &t
+	}
+	return *r.peeked, nil
+}
+
+func (r *PGNReader) next() (pgnToken, error) {
+	t, err := r.peek()
+	r.peeked = nil
+	return t, err
+}
+
+// scan reads the next token
+func (r *PGNReader) scan() (pgnToken, error) {
+	for {
+		c, err := r.readRune()
+		if err == io.EOF {
+			return pgnToken{kind: pgnTokenEOF, line: r.line, column: r.column}, nil
+		}
+		if err != nil {
+			return pgnToken{}, err
+		}
+		t := pgnToken{line: r.line, column: r.column}
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case c == '%' && r.column == 1:
+			// Escaped line
+			if _, err := r.readUntil('\n'); err != nil {
+				return t, err
+			}
+		case c == ';':
+			t.kind = pgnTokenComment
+			t.value, err = r.readUntil('\n')
+			t.value = strings.TrimSpace(t.value)
+			return t, err
+		case c == '{':
+			t.kind = pgnTokenComment
+			t.value, err = r.readUntil('}')
+			if err == io.EOF {
+				return t, t.errorf("unterminated comment")
+			}
+			t.value = strings.TrimSpace(strings.Join(strings.Fields(t.value), " "))
+			return t, err
+		case c == '"':
+			t.kind = pgnTokenString
+			t.value, err = r.readString()
+			if err == io.EOF {
+				return t, t.errorf("unterminated string")
+			}
+			return t, err
+		case c == '$':
+			t.kind = pgnTokenNAG
+			t.value, err = r.readSymbol()
+			if t.value == "" {
+				return t, t.errorf("invalid NAG")
+			}
+			return t, err
+		case c == '.':
+			t.kind, t.value = pgnTokenPeriod, "."
+			return t, nil
+		case c == '[':
+			t.kind, t.value = pgnTokenLeftBracket, "["
+			return t, nil
+		case c == ']':
+			t.kind, t.value = pgnTokenRightBracket, "]"
+			return t, nil
+		case c == '(':
+			t.kind, t.value = pgnTokenLeftParenthesis, "("
+			return t, nil
+		case c == ')':
+			t.kind, t.value = pgnTokenRightParenthesis, ")"
+			return t, nil
+		case c == '*':
+			t.kind, t.value = pgnTokenSymbol, "*"
+			return t, nil
+		case isSymbolRune(c) || c == '!' || c == '?':
+			r.unreadRune()
+			t.value, err = r.readSymbol()
+			t.kind = pgnTokenSymbol
+			if _, e := strconv.Atoi(t.value); e == nil {
+				t.kind = pgnTokenInteger
+			}
+			return t, err
+		default:
+			return t, t.errorf("unexpected character %q", c)
+		}
+	}
+}
+
+// readSymbol reads a symbol, with any suffix annotation
+func (r *PGNReader) readSymbol() (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := r.readRune()
+		if err == io.EOF {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if !isSymbolRune(c) && c != '!' && c != '?' {
+			r.unreadRune()
+			return sb.String(), nil
+		}
+		sb.WriteRune(c)
+	}
+}
+
+// readString reads a string after the opening quote, with \" and \\ escaped
+func (r *PGNReader) readString() (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := r.readRune()
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			c, err = r.readRune()
+			if err != nil {
+				return "", err
+			}
+		}
+		sb.WriteRune(c)
+	}
+}
+
+// readUntil reads up to and including end, and returns what was read before it
+func (r *PGNReader) readUntil(end rune) (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := r.readRune()
+		if err == io.EOF && end == '\n' {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return sb.String(), err
+		}
+		if c == end {
+			return sb.String(), nil
+		}
+		sb.WriteRune(c)
+	}
+}
+
+// readRune reads a rune and keeps track of the line and column
+func (r *PGNReader) readRune() (rune, error) {
+	c, _, err := r.r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	r.prevLine, r.prevColumn = r.line, r.column
+	if c == '\n' {
+		r.line++
+		r.column = 0
+	} else {
+		r.column++
+	}
+	return c, nil
+}
+
+// unreadRune unreads the last rune read, and restores the line and column
+func (r *PGNReader) unreadRune() {
+	_ = r.r.UnreadRune()
+	r.line, r.column = r.prevLine, r.prevColumn
+}
+
+func (t pgnToken) errorf(format string, args ...interface{}) error {
+	return &PGNError{Line: t.line, Column: t.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// sortTags puts the seven tag roster first, in order, followed by the other tags
+func sortTags(tags []PGNTag) []PGNTag {
+	sorted := make([]PGNTag, 0, len(tags))
+	for _, name := range SevenTagRoster {
+		for _, t := range tags {
+			if t.Name == name {
+				sorted = append(sorted, t)
+				break
+			}
+		}
+	}
+	for _, t := range tags {
+		if !isSevenTagRoster(t.Name) {
+			sorted = append(sorted, t)
+		}
+	}
+	return sorted
+}
+
+// startBoard returns the board that the game starts from
+func (g *PGNGame) startBoard() (*Board, error) {
+	if g.Game != nil {
+		return g.Game.Start(), nil
+	}
+	if fen := g.Tag("FEN"); fen != "" {
+		return FromFEN(fen)
+	}
+	return NewBoard(true), nil
+}
+
+// writeMoves writes a line of moves played from the board b. Black moves get a
+// move number at the start of the line, and when number is true.
+func (w *pgnWriter) writeMoves(b *Board, moves []PGNMove, number bool) error {
+	for i, m := range moves {
+		legal, err := b.checkValidMove(m.Move)
+		if err != nil {
+			return fmt.Errorf("invalid move %s : %s", m.Move, err.Error())
+		}
+
+		for _, c := range m.PreComments {
+			w.writeComment(c)
+			number = true
+		}
+		if b.ToMove() == ColorWhite {
+			w.write(fmt.Sprintf("%d.", b.MoveCount()))
+		} else if i == 0 || number {
+			w.write(fmt.Sprintf("%d...", b.MoveCount()))
+		}
+		number = false
+
+		w.write(b.ToSAN(legal))
+		for _, n := range m.NAGs {
+			w.write(fmt.Sprintf("$%d", n))
+		}
+		for _, c := range m.Comments {
+			w.writeComment(c)
+			number = true
+		}
+		for _, v := range m.Variations {
+			w.write("(")
+			if err := w.writeMoves(b, v, true); err != nil {
+				return err
+			}
+			w.write(")")
+			number = true
+		}
+
+		b = b.makeMove(legal)
+	}
+	return nil
+}
+
+// writeComment writes a comment, word by word so that it can be wrapped
+func (w *pgnWriter) writeComment(c string) {
+	words := strings.Fields(strings.ReplaceAll(c, "}", ""))
+	if len(words) == 0 {
+		w.write("{}")
+		return
+	}
+	words[0] = "{" + words[0]
+	words[len(words)-1] += "}"
+	for _, word := range words {
+		w.write(word)
+	}
+}
+
+// write writes a token, "(" is joined with the following token
+// and ")" with the preceding one
+func (w *pgnWriter) write(token string) {
+	switch {
+	case token == "(":
+		w.open = true
+		return
+	case token == ")" && w.line != "":
+		// The last token is wrapped with the ")" if the line gets too long
+		w.line += ")"
+		if i := strings.LastIndexByte(w.line, ' '); i >= 0 && len(w.line) > pgnLineLength {
+			w.lines = append(w.lines, w.line[:i])
+			w.line = w.line[i+1:]
+		}
+		return
+	case w.open:
+		token = "(" + token
+		w.open = false
+	}
+
+	if w.line != "" && len(w.line)+1+len(token) > pgnLineLength {
+		w.lines = append(w.lines, w.line)
+		w.line = ""
+	}
+	if w.line != "" {
+		w.line += " "
+	}
+	w.line += token
+}
+
+// String returns the written lines
+func (w *pgnWriter) String() string {
+	lines := w.lines
+	if w.line != "" {
+		lines = append(lines, w.line)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func writeTag(sb *strings.Builder, name, value string) {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	sb.WriteString(fmt.Sprintf("[%s \"%s\"]\n", name, value))
+}
+
+// unknownTagValue returns the value of a seven tag roster tag when it is unknown
+func unknownTagValue(name string) string {
+	switch name {
+	case "Date":
+		return "????.??.??"
+	case "Result":
+		return "*"
+	default:
+		return "?"
+	}
+}
+
+func isSevenTagRoster(name string) bool {
+	for _, n := range SevenTagRoster {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isResult(s string) bool {
+	return s == "1-0" || s == "0-1" || s == "1/2-1/2" || s == "*"
+}
+
+func isSymbolRune(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.ContainsRune("_+#=:-/", c)
+}
@@ -0,0 +1,268 @@
+package chess_engine
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPGN = `[Event "F/S Return Match"]
+[Site "Belgrade, Serbia JUG"]
+[Date "1992.11.04"]
+[Round "29"]
+[White "Fischer, Robert J."]
+[Black "Spassky, Boris V."]
+[Result "1/2-1/2"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 {This opening is called the Ruy Lopez.}
+4. Ba4 Nf6 5. O-O Be7 6. Re1 b5 7. Bb3 d6 8. c3 O-O 9. h3 Nb8 10. d4 Nbd7
+11. c4 c6 12. cxb5 axb5 13. Nc3 Bb7 14. Bg5 b4 15. Nb1 h6 16. Bh4 c5 17. dxe5
+Nxe4 18. Bxe7 Qxe7 19. exd6 Qf6 20. Nbd2 Nxd6 21. Nc4 Nxc4 22. Bxc4 Nb6
+23. Ne5 Rae8 24. Bxf7+ Rxf7 25. Nxf7 Rxe1+ 26. Qxe1 Kxf7 27. Qe3 Qg5 28. Qxg5
+hxg5 29. b3 Ke6 30. a3 Kd6 31. axb4 cxb4 32. Ra5 Nd5 33. f3 Bc8 34. Kf2 Bf5
+35. Ra7 g6 36. Ra6+ Kc5 37. Ke1 Nf4 38. g3 Nxh3 39. Kd2 Kb5 40. Rd6 Kc5 41. Ra6
+Nf2 42. g4 Bd3 43. Re6 1/2-1/2
+
+[Event "Annotated"]
+[White "A"]
+[Black "B"]
+[Site "?"]
+[Opening "Italian"]
+[Result "*"]
+
+{A short game} 1. e4 $1 e5 2. Nf3!? (2. f4 exf4 (2... d5) 3. Nf3) 2... Nc6 ; The main line
+3. Bc4?! *
+`
+
+func TestPGNReader_Read(t *testing.T) {
+	r := NewPGNReader(strings.NewReader(testPGN))
+
+	g, err := r.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(g.Tags))
+	assert.Equal(t, "Fischer, Robert J.", g.Tag("White"))
+	assert.Equal(t, "1/2-1/2", g.Result)
+	assert.Equal(t, 85, len(g.Moves))
+	assert.Equal(t, 85, g.Game.Length())
+	assert.Equal(t, "Nbd7", g.Moves[19].SAN)
+	assert.Equal(t, []string{"This opening is called the Ruy Lopez."}, g.Moves[5].Comments)
+	assert.Equal(t, "8/8/4R1p1/2k3p1/1p4P1/1P1b1P2/3K1n2/8 b - - 2 43", g.Game.Board().ToFEN())
+
+	g, err = r.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Event", "Site", "White", "Black", "Result", "Opening"}, tagNames(g.Tags))
+	assert.Equal(t, "*", g.Result)
+	assert.Equal(t, []string{"A short game"}, g.Comments)
+	assert.Equal(t, 5, len(g.Moves))
+	assert.Equal(t, []int{1}, g.Moves[0].NAGs)
+	assert.Equal(t, []int{5}, g.Moves[2].NAGs)
+	assert.Equal(t, []int{6}, g.Moves[4].NAGs)
+	assert.Equal(t, []string{"The main line"}, g.Moves[3].Comments)
+
+	// Variations start from the board before the move
+	assert.Equal(t, 1, len(g.Moves[2].Variations))
+	variation := g.Moves[2].Variations[0]
+	assert.Equal(t, []string{"f4", "exf4", "Nf3"}, moveSANs(variation))
+	assert.Equal(t, []string{"d5"}, moveSANs(variation[1].Variations[0]))
+
+	_, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestPGNReader_FEN(t *testing.T) {
+	pgn := `[FEN "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1"]
+[SetUp "1"]
+
+1. Ra8# 1-0`
+	games, err := ReadPGN(strings.NewReader(pgn))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, "1-0", games[0].Result)
+	assert.True(t, games[0].Game.Status().IsOver())
+}
+
+func TestPGNReader_MissingResult(t *testing.T) {
+	games, err := ReadPGN(strings.NewReader("1. e4 e5\n\n[Event \"Next\"]\n1. d4 d5"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(games))
+	assert.Equal(t, "*", games[0].Result)
+	assert.Equal(t, "Next", games[1].Tag("Event"))
+	assert.Equal(t, "d5", games[1].Moves[1].SAN)
+}
+
+func TestPGNReader_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		pgn  string
+		want string
+	}{
+		{"Illegal move", "1. e4 e5 2. Ke3 *", "line 1, column 13 : illegal move : Ke3"},
+		{"Invalid move", "1. e4 Zz5 *", "line 1, column 7 : invalid move : Zz5"},
+		{"Ambiguous move", "[FEN \"k7/8/8/8/8/8/8/KR3R2 w - - 0 1\"]\n1. Rd1 *", "line 2, column 4 : ambiguous move : Rd1"},
+		{"Unterminated comment", "1. e4 {comment", "line 1, column 7 : unterminated comment"},
+		{"Unterminated string", "[Event \"x]\n", "line 1, column 8 : unterminated string"},
+		{"Unterminated variation", "1. e4 (1. d4 *", "line 1, column 14 : result in a variation"},
+		{"Empty variation", "1. e4 ( {comment} ) e5 *", "line 1, column 7 : empty variation"},
+		{"Missing tag value", "[Event]\n1. e4 *", "line 1, column 7 : expected a tag value"},
+		{"Unexpected character", "1. e4 & *", "line 1, column 7 : unexpected character '&'"},
+		{"Invalid FEN", "[FEN \"8/8 w\"]\n1. e4 *", "line 1, column 1 : invalid FEN tag : 8/8 w"},
+		{"Invalid promotion", "[FEN \"k7/4P3/8/8/8/8/8/K7 w - - 0 1\"]\n1. e8=K *", "line 2, column 4 : invalid promotion : e8=K"},
+		{"Lines ending with moves", "[Event \"x\"]\n\n1. e4 e5\n2. Nf3 Nc6\n3. Bb5 Qx9 *", "line 5, column 8 : invalid move : Qx9"},
+		{"Lines ending with NAGs", "1. e4 $1\n1... e5 $2\n2. Ke3 *", "line 3, column 4 : illegal move : Ke3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPGNReader(strings.NewReader(tt.pgn)).Read()
+			assert.NotNil(t, err)
+			assert.IsType(t, &PGNError{}, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestPGNReader_ContinueAfterError(t *testing.T) {
+	tests := []struct {
+		name string
+		pgn  string
+	}{
+		{"Illegal move", "[Event \"Bad\"]\n1. e4 e4 *\n\n[Event \"Good\"]\n1. e4 e5 *\n"},
+		{"Unterminated variation", "1. e4 (1. d4\n[Event \"Good\"]\n1. e4 *\n"},
+		{"Missing tag value", "[Event\n[Event \"Good\"]\n1. e4 *\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewPGNReader(strings.NewReader(tt.pgn))
+
+			_, err := r.Read()
+			assert.NotNil(t, err)
+			g, err := r.Read()
+			assert.Nil(t, err)
+			assert.Equal(t, "Good", g.Tag("Event"))
+			_, err = r.Read()
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestPGNGame_Write(t *testing.T) {
+	g := NewGame(NewBoard(true))
+	for _, s := range []string{"f2f3", "e7e5", "g2g4", "d8h4"} {
+		m, _ := ParseMove(s)
+		assert.Nil(t, g.Play(m))
+	}
+	p := NewPGNGame(g)
+	p.SetTag("White", "Fool")
+	p.SetTag("Annotator", "Me")
+	p.Comments = []string{"Fool's mate"}
+	p.Moves[1].Comments = []string{"Black is better"}
+	p.Moves[2].NAGs = []int{4}
+	p.Moves[2].Variations = [][]PGNMove{{{Move: NewMove(Alg("e2"), Alg("e4"), PieceNone, 0)}}}
+
+	want := `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "Fool"]
+[Black "?"]
+[Result "0-1"]
+[Annotator "Me"]
+
+{Fool's mate} 1. f3 e5 {Black is better} 2. g4 $4 (2. e4) 2... Qh4# 0-1
+`
+	assert.Equal(t, want, p.String())
+}
+
+func TestPGNGame_WriteFEN(t *testing.T) {
+	b, _ := FromFEN("6k1/5ppp/8/8/8/8/8/R5K1 b - - 0 1")
+	g := NewGame(b)
+	m, _ := ParseMove("g8h8")
+	assert.Nil(t, g.Play(m))
+	m, _ = ParseMove("a1a8")
+	assert.Nil(t, g.Play(m))
+
+	s := NewPGNGame(g).String()
+	assert.Contains(t, s, "[Result \"1-0\"]\n[SetUp \"1\"]\n[FEN \"6k1/5ppp/8/8/8/8/8/R5K1 b - - 0 1\"]\n")
+	assert.True(t, strings.HasSuffix(s, "\n1... Kh8 2. Ra8# 1-0\n"), s)
+}
+
+func TestPGNGame_RoundTrip(t *testing.T) {
+	pgn := testPGN + "\n1. e4 ( {alt start} 1. d4 d5 ) e5 *\n"
+	games, err := ReadPGN(strings.NewReader(pgn))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(games))
+	assert.Equal(t, []string{"alt start"}, games[2].Moves[0].Variations[0][0].PreComments)
+
+	var sb strings.Builder
+	assert.Nil(t, WritePGN(&sb, games))
+	assert.Contains(t, sb.String(), "\n1. e4 ({alt start} 1. d4 d5) 1... e5 *\n")
+	for _, line := range strings.Split(sb.String(), "\n") {
+		assert.LessOrEqual(t, len(line), 80, line)
+	}
+
+	again, err := ReadPGN(strings.NewReader(sb.String()))
+	assert.Nil(t, err)
+	assert.Equal(t, len(games), len(again))
+	for i := range games {
+		for _, tag := range games[i].Tags {
+			assert.Equal(t, tag.Value, again[i].Tag(tag.Name))
+		}
+		assert.Equal(t, games[i].Comments, again[i].Comments)
+		assert.Equal(t, games[i].Moves, again[i].Moves)
+		assert.Equal(t, games[i].Result, again[i].Result)
+	}
+
+	// Writing the games again gives the same output
+	var sb2 strings.Builder
+	assert.Nil(t, WritePGN(&sb2, again))
+	assert.Equal(t, sb.String(), sb2.String())
+}
+
+func TestPGNGame_WriteLineLength(t *testing.T) {
+	e4 := NewMove(Alg("e2"), Alg("e4"), PieceNone, 0)
+	d4 := NewMove(Alg("d2"), Alg("d4"), PieceNone, 0)
+	d5 := NewMove(Alg("d7"), Alg("d5"), PieceNone, 0)
+	e5 := NewMove(Alg("e7"), Alg("e5"), PieceNone, 0)
+
+	// Comments of every length, so that a ")" or "))" ends up at every column
+	for n := 1; n <= 76; n++ {
+		comment := strings.Repeat("x", n)
+		variation := []PGNMove{{Move: d4}, {Move: d5, Comments: []string{comment}, Variations: [][]PGNMove{{{Move: e5, Comments: []string{comment}}}}}}
+		p := &PGNGame{Moves: []PGNMove{{Move: e4, Variations: [][]PGNMove{variation}}}}
+		s := p.String()
+		for _, line := range strings.Split(s, "\n") {
+			assert.LessOrEqual(t, len(line), 80, "comment length %d : %q", n, line)
+		}
+
+		// The wrapped game is read back the same way
+		g, err := NewPGNReader(strings.NewReader(s)).Read()
+		assert.Nil(t, err, s)
+		assert.Equal(t, []string{comment}, g.Moves[0].Variations[0][1].Variations[0][0].Comments)
+	}
+}
+
+func TestPGNGame_WriteIllegalMove(t *testing.T) {
+	p := &PGNGame{Moves: []PGNMove{{Move: NewMove(Alg("e2"), Alg("e5"), PieceNone, 0)}}}
+	assert.NotNil(t, p.Write(&strings.Builder{}))
+}
+
+//
+// Private functions
+//
+
+func tagNames(tags []PGNTag) []string {
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func moveSANs(moves []PGNMove) []string {
+	sans := make([]string, len(moves))
+	for i, m := range moves {
+		sans[i] = m.SAN
+	}
+	return sans
+}
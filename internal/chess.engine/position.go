@@ -58,3 +58,8 @@ func (p Position) ToXY() (int, int) {
 func (p Position) ToAlg() string {
 	return fmt.Sprintf("%c%v", byte(p%8+97), p/8+1)
 }
+
+// onBoard returns true if the coordinates (1-8, 1-8) are on the board
+func onBoard(x, y int) bool {
+	return x >= 1 && x <= 8 && y >= 1 && y <= 8
+}
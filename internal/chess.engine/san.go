@@ -0,0 +1,187 @@
+package chess_engine
+
+import (
+	"errors"
+	"strings"
+)
+
+// ParseSAN parses a move in standard algebraic notation (i e "Nf3", "exd5",
+// "O-O" or "e8=Q+"), and returns the matching legal move. The parser is
+// lenient, it also accepts i e "0-0", "exd6 e.p.", "e8Q", "e8q" and "Ng1-f3" :
+// https://en.wikipedia.org/wiki/Algebraic_notation_(chess)
+func (b *Board) ParseSAN(san string) (Move, error) {
+	s := trimSANSuffix(san)
+	if strings.HasSuffix(s, "e.p.") {
+		s = trimSANSuffix(strings.TrimSuffix(s, "e.p."))
+	}
+	if s == "" {
+		return NoMove, errors.New("invalid move : " + san)
+	}
+
+	moves := NewMover().GenerateLegalMoves(b)
+
+	// Castling
+	switch strings.ReplaceAll(s, "0", "O") {
+	case "O-O", "O-O-O":
+		long := len(s) == 5
+		for _, m := range moves {
+			if m.IsCastle() && (m.To() < m.From()) == long {
+				return m, nil
+			}
+		}
+		return NoMove, errors.New("illegal move : " + san)
+	}
+
+	// Piece, pawns have no letter
+	piece := PieceWhitePawn
+	if strings.ContainsRune("NBRQK", rune(s[0])) {
+		piece = getPieceFromLetter(s[:1])
+		s = s[1:]
+	} else if s[0] == 'P' {
+		s = s[1:]
+	}
+
+	// Promotion, with or without the =
+	promotion := PieceNone
+	if i := strings.IndexByte(s, '='); i >= 0 {
+		if i != len(s)-2 || !strings.ContainsRune("NBRQ", rune(strings.ToUpper(s[i+1:])[0])) {
+			return NoMove, errors.New("invalid promotion : " + san)
+		}
+		promotion = getPieceFromLetter(strings.ToUpper(s[i+1:]))
+		s = s[:i]
+	} else if len(s) >= 3 && strings.ContainsRune("NBRQnbrq", rune(s[len(s)-1])) && isSquare(s[len(s)-3:len(s)-1]) {
+		promotion = getPieceFromLetter(strings.ToUpper(s[len(s)-1:]))
+		s = s[:len(s)-1]
+	}
+
+	// Destination, and the from file and/or rank when needed to disambiguate
+	if len(s) < 2 || !isSquare(s[len(s)-2:]) {
+		return NoMove, errors.New("invalid move : " + san)
+	}
+	to := Alg(s[len(s)-2:])
+	fromFile, fromRank := 0, 0
+	for _, c := range strings.TrimRight(s[:len(s)-2], "x:-") {
+		switch {
+		case c >= 'a' && c <= 'h' && fromFile == 0:
+			fromFile = int(c-'a') + 1
+		case c >= '1' && c <= '8' && fromRank == 0:
+			fromRank = int(c-'1') + 1
+		default:
+			return NoMove, errors.New("invalid move : " + san)
+		}
+	}
+
+	found := NoMove
+	for _, m := range moves {
+		x, y := m.From().ToXY()
+		if m.To() != to || getPieceType(b.Piece(m.From())) != piece ||
+			getPieceType(m.Promotion()) != promotion ||
+			(fromFile != 0 && x != fromFile) || (fromRank != 0 && y != fromRank) {
+			continue
+		}
+		if found != NoMove {
+			return NoMove, errors.New("ambiguous move : " + san)
+		}
+		found = m
+	}
+	if found == NoMove {
+		return NoMove, errors.New("illegal move : " + san)
+	}
+
+	return found, nil
+}
+
+// ToSAN returns the legal move m in standard algebraic notation (i e "Nbd7",
+// "exd6", "O-O" or "e8=Q#"), or its coordinate notation if it is not legal
+func (b *Board) ToSAN(m Move) string {
+	moves := NewMover().GenerateLegalMoves(b)
+	legal := NoMove
+	for _, lm := range moves {
+		if lm.sameMove(m) {
+			legal = lm
+			break
+		}
+	}
+	if legal == NoMove {
+		return m.String()
+	}
+	m = legal
+
+	var sb strings.Builder
+	piece := getPieceType(b.Piece(m.From()))
+	switch {
+	case m.IsCastle() && m.To() < m.From():
+		sb.WriteString("O-O-O")
+	case m.IsCastle():
+		sb.WriteString("O-O")
+	case piece == PieceWhitePawn:
+		if m.IsCapture() {
+			sb.WriteByte(m.From().ToAlg()[0])
+			sb.WriteByte('x')
+		}
+		sb.WriteString(m.To().ToAlg())
+		if m.IsPromotion() {
+			sb.WriteString("=" + getLetterFromPiece(getPieceType(m.Promotion())))
+		}
+	default:
+		sb.WriteString(getLetterFromPiece(piece))
+		sb.WriteString(b.disambiguate(m, moves))
+		if m.IsCapture() {
+			sb.WriteByte('x')
+		}
+		sb.WriteString(m.To().ToAlg())
+	}
+
+	after := b.makeMove(m)
+	if after.InCheck() {
+		if len(NewMover().GenerateLegalMoves(after)) == 0 {
+			sb.WriteByte('#')
+		} else {
+			sb.WriteByte('+')
+		}
+	}
+
+	return sb.String()
+}
+
+//
+// Private functions
+//
+
+// disambiguate returns the from file, rank or square needed to tell the piece
+// move m apart from other legal moves by the same kind of piece to the same square
+func (b *Board) disambiguate(m Move, moves []Move) string {
+	x, y := m.From().ToXY()
+	ambiguous, sameFile, sameRank := false, false, false
+	for _, o := range moves {
+		if o.To() != m.To() || o.From() == m.From() || b.Piece(o.From()) != b.Piece(m.From()) {
+			continue
+		}
+		ambiguous = true
+		ox, oy := o.From().ToXY()
+		sameFile = sameFile || ox == x
+		sameRank = sameRank || oy == y
+	}
+
+	from := m.From().ToAlg()
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return from[:1]
+	case !sameRank:
+		return from[1:]
+	default:
+		return from
+	}
+}
+
+// isSquare returns true if s is a square in algebraic notation (i e "e4")
+func isSquare(s string) bool {
+	return len(s) == 2 && s[0] >= 'a' && s[0] <= 'h' && s[1] >= '1' && s[1] <= '8'
+}
+
+// trimSANSuffix removes check, mate and annotation suffixes, and white space
+func trimSANSuffix(s string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(s), "+#!?"))
+}
@@ -0,0 +1,140 @@
+package chess_engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	sanStart     = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	sanCastling  = "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+	sanEnPassant = "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1"
+	sanPromotion = "1n2k3/2P5/8/8/8/8/8/4K3 w - - 0 1"
+	sanKnights   = "r3k3/8/8/8/8/8/8/1N1NK3 w - - 0 1"
+	sanRooks     = "4k3/8/8/R7/8/8/8/R3K2R w - - 0 1"
+	sanQueens    = "4k3/8/8/8/8/Q7/8/Q1Q1K3 w - - 0 1"
+	sanMate      = "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1"
+)
+
+func TestBoard_ToSAN(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move string
+		want string
+	}{
+		{"Pawn push", sanStart, "e2e4", "e4"},
+		{"Knight", sanStart, "g1f3", "Nf3"},
+		{"Short castling", sanCastling, "e1g1", "O-O"},
+		{"Long castling", sanCastling, "e1c1", "O-O-O"},
+		{"Rook capture", sanCastling, "h1h8", "Rxh8+"},
+		{"En passant", sanEnPassant, "e5d6", "exd6"},
+		{"Promotion", sanPromotion, "c7c8q", "c8=Q+"},
+		{"Promotion capture", sanPromotion, "c7b8n", "cxb8=N"},
+		{"File disambiguation", sanKnights, "b1c3", "Nbc3"},
+		{"Rank disambiguation", sanRooks, "a1a3", "R1a3"},
+		{"Square disambiguation", sanQueens, "a1b2", "Qa1b2"},
+		{"No disambiguation", sanQueens, "a3a8", "Qa8+"},
+		{"Mate", sanMate, "a1a8", "Ra8#"},
+		{"Illegal", sanStart, "e2e5", "e2e5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			m, err := ParseMove(tt.move)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, b.ToSAN(m))
+		})
+	}
+}
+
+func TestBoard_ParseSAN(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		san  string
+		want string
+	}{
+		{"Pawn push", sanStart, "e4", "e2e4"},
+		{"Long algebraic", sanStart, "e2-e4", "e2e4"},
+		{"Pawn letter", sanStart, "Pe4", "e2e4"},
+		{"Knight", sanStart, "Nf3", "g1f3"},
+		{"Knight with from square", sanStart, "Ng1-f3", "g1f3"},
+		{"Short castling", sanCastling, "O-O", "e1g1"},
+		{"Long castling", sanCastling, "O-O-O", "e1c1"},
+		{"Castling with zeros", sanCastling, "0-0", "e1g1"},
+		{"Long castling with zeros", sanCastling, "0-0-0+", "e1c1"},
+		{"Capture with check", sanCastling, "Rxh8+", "h1h8"},
+		{"Capture without x", sanCastling, "Rh8", "h1h8"},
+		{"En passant", sanEnPassant, "exd6", "e5d6"},
+		{"En passant suffix", sanEnPassant, "exd6 e.p.", "e5d6"},
+		{"En passant suffix without space", sanEnPassant, "exd6e.p.", "e5d6"},
+		{"Promotion", sanPromotion, "c8=Q+", "c7c8q"},
+		{"Promotion without =", sanPromotion, "c8Q", "c7c8q"},
+		{"Promotion without = lower case", sanPromotion, "c8q", "c7c8q"},
+		{"Promotion to bishop without = lower case", sanPromotion, "c8b", "c7c8b"},
+		{"Promotion capture without = lower case", sanPromotion, "cxb8n+", "c7b8n"},
+		{"Promotion lower case", sanPromotion, "c8=r", "c7c8r"},
+		{"Promotion capture", sanPromotion, "cxb8=N", "c7b8n"},
+		{"File disambiguation", sanKnights, "Nbc3", "b1c3"},
+		{"Rank disambiguation", sanRooks, "R1a3", "a1a3"},
+		{"Square disambiguation", sanQueens, "Qa1b2", "a1b2"},
+		{"Mate with annotation", sanMate, "Ra8#!!", "a1a8"},
+		{"Not needed disambiguation", sanStart, "Ngf3", "g1f3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			m, err := b.ParseSAN(tt.san)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, m.String())
+		})
+	}
+}
+
+func TestBoard_ParseSAN_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		san  string
+		want string
+	}{
+		{"Empty", sanStart, "+", "invalid move : +"},
+		{"No square", sanStart, "N", "invalid move : N"},
+		{"Invalid square", sanStart, "Ni9", "invalid move : Ni9"},
+		{"Invalid disambiguation", sanStart, "Nzf3", "invalid move : Nzf3"},
+		{"Illegal", sanStart, "e5", "illegal move : e5"},
+		{"Illegal castling", sanStart, "O-O", "illegal move : O-O"},
+		{"Ambiguous", sanKnights, "Nc3", "ambiguous move : Nc3"},
+		{"Ambiguous file", sanQueens, "Qab2", "ambiguous move : Qab2"},
+		{"Invalid promotion", sanPromotion, "c8=K", "invalid promotion : c8=K"},
+		{"Missing promotion", sanPromotion, "c8", "illegal move : c8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			m, err := b.ParseSAN(tt.san)
+			assert.Equal(t, NoMove, m)
+			assert.NotNil(t, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestBoard_SANRoundTrip(t *testing.T) {
+	// Every legal move is parsed back from its SAN
+	for _, fen := range []string{sanStart, sanCastling, sanEnPassant, sanPromotion, sanKnights, sanRooks, sanQueens, sanMate} {
+		b, err := FromFEN(fen)
+		assert.Nil(t, err)
+		for _, m := range NewMover().GenerateLegalMoves(b) {
+			san := b.ToSAN(m)
+			got, err := b.ParseSAN(san)
+			assert.Nil(t, err, san)
+			assert.Equal(t, m, got, san)
+		}
+	}
+}
@@ -0,0 +1,483 @@
+package chess_engine
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+const (
+	// scoreMate is the score for giving checkmate, a mate in n plies is
+	// scored scoreMate-n, so that shorter mates are preferred
+	scoreMate     = 32000
+	scoreInfinity = 32001
+
+	// maxPly is the maximum search depth, in plies
+	maxPly = 128
+)
+
+// Selective search parameters, depths in plies and margins in centipawns
+//  - nullMoveDepth          - minimum depth for null move pruning
+//  - reverseFutilityDepth  - maximum depth for reverse futility pruning
+//  - reverseFutilityMargin - margin per ply of depth for reverse futility pruning
+//  - lmrDepth              - minimum depth for late move reductions
+//  - lmrMoves              - number of moves searched before late moves are reduced
+const (
+	nullMoveDepth         = 3
+	reverseFutilityDepth  = 3
+	reverseFutilityMargin = 120
+	lmrDepth              = 3
+	lmrMoves              = 3
+)
+
+// futilityMargins are the margins for futility pruning, by depth
+var futilityMargins = [...]int{0, 200, 500}
+
+// SearchResult contains the result of a search
+//  - Move  - the best move, NoMove if there are no legal moves
+//  - Score - the score in centipawns from the point of view of the color to move, see MateIn
+//  - PV    - the principal variation, the expected line of play starting with Move
+//  - Depth - the depth searched, in plies
+//  - Nodes - the number of positions visited
+//  - Lines - the best lines, best first, when searching for more than one best move (MultiPV)
+type SearchResult struct {
+	Move  Move
+	Score int
+	PV    []Move
+	Depth int
+	Nodes uint64
+	Lines []SearchLine
+}
+
+// SearchLine is one of the best lines found by a search
+//  - Move  - the first move of the line
+//  - Score - the score in centipawns from the point of view of the color to move, see MateIn
+//  - Depth - the depth searched, in plies
+//  - PV    - the principal variation, the expected line of play starting with Move
+type SearchLine struct {
+	Move  Move
+	Score int
+	Depth int
+	PV    []Move
+}
+
+// searchWorker holds the state of a single search
+//  - control      - decides when to stop, nil if the search can't be stopped
+//  - canStop      - false until the first depth has been searched
+//  - tt           - the transposition table, can be shared with other workers
+//  - hashes       - hashes of the boards from the start of the game to the current board
+//  - pv, pvLength - triangular table with the principal variation found at each ply
+//  - killers      - two quiet moves per ply that caused beta cutoffs
+//  - history      - how much quiet moves, per color, from and to position, have caused beta cutoffs
+//  - nullMove     - true if the last move was a null move
+//  - helper       - true if the worker is a helper thread in a multi-threaded search
+//  - excluded     - root moves that are not searched, since they belong to earlier lines
+type searchWorker struct {
+	mover    *Mover
+	config   SearchConfig
+	tt       *TranspositionTable
+	control  *searchControl
+	canStop  bool
+	nodes    uint64
+	hashes   []uint64
+	pv       [maxPly][maxPly]Move
+	pvLength [maxPly]int
+	killers  [maxPly][2]Move
+	history  [2][64][64]int
+	nullMove bool
+	helper   bool
+	excluded []Move
+}
+
+// checkInterval is how often, in nodes, the search checks if it has to stop
+const checkInterval = 2048
+
+// searchHashSize is the transposition table size in MB for one-off searches
+// with Search, a fixed depth search doesn't need the default size
+const searchHashSize = 1
+
+// Search searches the board depth plies deep using negamax with alpha-beta
+// pruning, and Board.Value to evaluate the positions at the leaves :
+// https://www.chessprogramming.org/Alpha-Beta
+func Search(b *Board, depth int) SearchResult {
+	if depth < 1 {
+		depth = 1
+	}
+
+	return newSearcher(searchHashSize).Think(context.Background(), NewGame(b), SearchLimits{Depth: depth}, nil)
+}
+
+// MateIn converts a search score to the number of moves to mate, which is
+// positive if the color to move mates and negative if it is mated. It
+// returns false if the score is not a mate score.
+func MateIn(score int) (int, bool) {
+	switch {
+	case score > scoreMate-maxPly:
+		return (scoreMate - score + 1) / 2, true
+	case score < -scoreMate+maxPly:
+		return -(scoreMate + score) / 2, true
+	}
+	return 0, false
+}
+
+//
+// Private functions
+//
+
+func newSearchWorker(config SearchConfig, tt *TranspositionTable) *searchWorker {
+	return &searchWorker{mover: NewMover(), config: config, tt: tt}
+}
+
+// negamax returns the score of the board b from the point of view of the
+// color to move, searched depth plies deep. The board is ply plies from
+// the root, and only scores between alpha and beta are exact.
+func (w *searchWorker) negamax(b *Board, depth, ply, alpha, beta int) int {
+	w.nodes++
+	w.pvLength[ply] = ply
+	afterNullMove := w.nullMove
+	w.nullMove = false
+
+	if w.shouldStop() {
+		return 0
+	}
+	if ply > 0 && (b.isDraw() || w.isRepetition(b)) {
+		return 0
+	}
+
+	// Check extension, positions in check are searched one ply deeper,
+	// so that the search doesn't end in the middle of a forcing line
+	inCheck := b.InCheck()
+	if inCheck && w.config.CheckExtension {
+		depth++
+	}
+	if depth <= 0 || ply >= maxPly-1 {
+		return w.quiescence(b, ply, alpha, beta)
+	}
+
+	// Use the score from an earlier search of this position if it is deep
+	// enough. Exact scores are not used in the principal variation, since
+	// that would cut it short.
+	pvNode := beta-alpha > 1
+	// The root entry is not used or stored when moves are excluded, since
+	// the score is not the score of the board
+	rootExcluded := ply == 0 && len(w.excluded) > 0
+	hashMove := NoMove
+	if e, ok := w.tt.probe(b.Hash(), ply); ok && !rootExcluded {
+		hashMove = e.move
+		if e.depth >= depth &&
+			((e.bound == ttBoundExact && !pvNode) ||
+				(e.bound == ttBoundLower && e.score >= beta) ||
+				(e.bound == ttBoundUpper && e.score <= alpha)) {
+			return e.score
+		}
+	}
+
+	eval := 0
+	if !pvNode && !inCheck {
+		eval = b.evaluate()
+
+		// Reverse futility pruning, the position is so good that
+		// the opponent will avoid it
+		if w.config.ReverseFutility && depth <= reverseFutilityDepth && !isMateScore(beta) &&
+			eval-reverseFutilityMargin*depth >= beta {
+			return eval
+		}
+
+		if w.config.NullMove && w.nullMoveCutoff(b, eval, depth, ply, beta, afterNullMove) {
+			return beta
+		}
+	}
+
+	moves := w.mover.GenerateLegalMoves(b)
+	if rootExcluded {
+		moves = w.excludeMoves(moves)
+	}
+	if len(moves) == 0 {
+		if inCheck {
+			return -scoreMate + ply
+		}
+		return 0
+	}
+	scores := w.orderMoves(b, moves, ply, hashMove)
+
+	// Futility pruning, quiet moves can't raise the score above alpha
+	futile := w.config.Futility && !pvNode && !inCheck && depth < len(futilityMargins) &&
+		!isMateScore(alpha) && eval+futilityMargins[depth] <= alpha
+
+	alphaOrig := alpha
+	best, bestMove := -scoreInfinity, NoMove
+	for i := range moves {
+		m := pickMove(moves, scores, i)
+		nb := b.makeMove(m)
+		quiet := m.IsQuiet() && !nb.InCheck()
+		if futile && i > 0 && quiet {
+			continue
+		}
+
+		// Late move reductions, moves late in the ordering are unlikely to be
+		// good, so they are searched less deep, and again at full depth only
+		// if they turn out to raise alpha
+		r := 0
+		if w.config.LateMoveReductions && depth >= lmrDepth && i >= lmrMoves &&
+			quiet && !inCheck && !w.isKiller(m, ply) {
+			r = lmrReduction(depth, i)
+		}
+
+		score := 0
+		switch {
+		case i == 0:
+			score = -w.search(nb, depth-1, ply+1, -beta, -alpha)
+		case w.config.PVS:
+			// Principal variation search, the first move is expected to be
+			// the best, so the others are searched with a zero window that
+			// only proves that they are worse, and searched again if not :
+			// https://www.chessprogramming.org/Principal_Variation_Search
+			score = -w.search(nb, depth-1-r, ply+1, -alpha-1, -alpha)
+			if score > alpha && (r > 0 || score < beta) {
+				score = -w.search(nb, depth-1, ply+1, -beta, -alpha)
+			}
+		default:
+			score = -w.search(nb, depth-1-r, ply+1, -beta, -alpha)
+			if r > 0 && score > alpha {
+				score = -w.search(nb, depth-1, ply+1, -beta, -alpha)
+			}
+		}
+		if w.stopped() {
+			return 0
+		}
+
+		if score > best {
+			best, bestMove = score, m
+		}
+		if score > alpha {
+			alpha = score
+			w.updatePV(ply, m)
+			if alpha >= beta {
+				w.updateCutoff(b, m, ply, depth)
+				break
+			}
+		}
+	}
+
+	bound := ttBoundExact
+	if best >= beta {
+		bound = ttBoundLower
+	} else if best <= alphaOrig {
+		bound = ttBoundUpper
+	}
+	if !rootExcluded {
+		w.tt.store(b.Hash(), ply, bestMove, best, depth, bound)
+	}
+
+	return best
+}
+
+// nullMoveCutoff returns true if the position is so good that, even if the
+// color to move passes, a reduced search fails high. The null move is not
+// tried twice in a row, and not in positions where zugzwang is likely, i e
+// when the color to move has no pieces except pawns and the king :
+// https://www.chessprogramming.org/Null_Move_Pruning
+func (w *searchWorker) nullMoveCutoff(b *Board, eval, depth, ply, beta int, afterNullMove bool) bool {
+	if afterNullMove || ply == 0 || depth < nullMoveDepth || eval < beta ||
+		isMateScore(beta) || !b.hasPieces(b.ToMove()) {
+		return false
+	}
+
+	r := 2
+	if depth >= 6 {
+		r = 3
+	}
+	w.nullMove = true
+	score := -w.search(b.makeNullMove(), depth-1-r, ply+1, -beta, -beta+1)
+	w.nullMove = false
+
+	return !w.stopped() && score >= beta
+}
+
+// excludeMoves removes the excluded root moves from moves
+func (w *searchWorker) excludeMoves(moves []Move) []Move {
+	included := moves[:0]
+	for _, m := range moves {
+		excluded := false
+		for _, e := range w.excluded {
+			if m == e {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			included = append(included, m)
+		}
+	}
+
+	return included
+}
+
+// lmrReduction returns how many plies the i:th move is reduced at depth
+// by late move reductions : https://www.chessprogramming.org/Late_Move_Reductions
+func lmrReduction(depth, i int) int {
+	if depth >= 6 && i >= 2*lmrMoves {
+		return 2
+	}
+	return 1
+}
+
+// isKiller returns true if m is one of the killer moves at ply
+func (w *searchWorker) isKiller(m Move, ply int) bool {
+	return w.config.KillerMoves && (m == w.killers[ply][0] || m == w.killers[ply][1])
+}
+
+// isMateScore returns true if the score is a mate score
+func isMateScore(score int) bool {
+	return score > scoreMate-maxPly || score < -scoreMate+maxPly
+}
+
+// quiescence extends the search at the leaves with captures and promotions
+// until the position is quiet, so that the evaluation is not done in the
+// middle of an exchange. The side to move can always stand pat, i e choose
+// not to capture : https://www.chessprogramming.org/Quiescence_Search
+func (w *searchWorker) quiescence(b *Board, ply, alpha, beta int) int {
+	w.nodes++
+	w.pvLength[ply] = ply
+
+	if w.shouldStop() {
+		return 0
+	}
+
+	best := b.evaluate()
+	if best >= beta || ply >= maxPly-1 {
+		return best
+	}
+	if best > alpha {
+		alpha = best
+	}
+
+	for _, m := range w.captures(b) {
+		score := -w.quiescence(b.makeMove(m), ply+1, -beta, -alpha)
+		if w.stopped() {
+			return 0
+		}
+
+		if score > best {
+			best = score
+		}
+		if score > alpha {
+			alpha = score
+			if alpha >= beta {
+				break
+			}
+		}
+	}
+
+	return best
+}
+
+// captures returns the legal captures and promotions, with the most valuable
+// victims first and, for the same victim, the least valuable attackers first
+// (MVV-LVA), which makes beta cutoffs happen early in the quiescence search
+func (w *searchWorker) captures(b *Board) []Move {
+	moves := w.mover.GenerateMoves(b)
+	c := b.ToMove()
+
+	captures := moves[:0]
+	scores := make([]int, 0, len(moves))
+	for _, m := range moves {
+		// Only the legality of captures has to be checked
+		if m.IsQuiet() || b.makeMove(m).isKingAttacked(c) {
+			continue
+		}
+		captures = append(captures, m)
+		scores = append(scores, b.mvvLva(m))
+	}
+
+	// Insertion sort, the lists are short
+	for i := 1; i < len(captures); i++ {
+		for j := i; j > 0 && scores[j] > scores[j-1]; j-- {
+			captures[j], captures[j-1] = captures[j-1], captures[j]
+			scores[j], scores[j-1] = scores[j-1], scores[j]
+		}
+	}
+
+	return captures
+}
+
+// search searches the board b, after a move has been made, and keeps
+// track of the board history while doing so
+func (w *searchWorker) search(b *Board, depth, ply, alpha, beta int) int {
+	w.hashes = append(w.hashes, b.Hash())
+	score := w.negamax(b, depth, ply, alpha, beta)
+	w.hashes = w.hashes[:len(w.hashes)-1]
+
+	return score
+}
+
+// shouldStop returns true if the search has to stop, the first
+// depth is always finished so that there is a move to play
+func (w *searchWorker) shouldStop() bool {
+	if w.control == nil || !w.canStop {
+		return false
+	}
+	if w.helper {
+		// Helpers only count nodes, the main thread checks the node limit
+		if w.nodes%checkInterval == 0 {
+			atomic.AddUint64(&w.control.helperNodes, checkInterval)
+			return w.control.check(0)
+		}
+		return w.control.isStopped()
+	}
+	if w.nodes%checkInterval == 0 || w.nodes == w.control.nodes {
+		return w.control.check(w.nodes + w.control.helperCount())
+	}
+	return w.control.isStopped()
+}
+
+// stopped returns true if the search has been stopped
+func (w *searchWorker) stopped() bool {
+	return w.control != nil && w.canStop && w.control.isStopped()
+}
+
+// isRepetition returns true if the board b, the last board in the hashes,
+// has occurred before. Only boards since the last capture or pawn move
+// with the same color to move need to be checked. A single repetition is
+// scored as a draw, since the side that can repeat once can repeat again.
+func (w *searchWorker) isRepetition(b *Board) bool {
+	last := len(w.hashes) - 1
+	first := last - b.HalfMoveCount()
+	if first < 0 {
+		first = 0
+	}
+	for i := last - 2; i >= first; i -= 2 {
+		if w.hashes[i] == b.Hash() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updatePV sets the principal variation at ply to the move
+// m followed by the principal variation at ply+1
+func (w *searchWorker) updatePV(ply int, m Move) {
+	w.pv[ply][ply] = m
+	for i := ply + 1; i < w.pvLength[ply+1]; i++ {
+		w.pv[ply][i] = w.pv[ply+1][i]
+	}
+	w.pvLength[ply] = w.pvLength[ply+1]
+}
+
+// line returns the best line of the search result
+func (r SearchResult) line() SearchLine {
+	return SearchLine{Move: r.Move, Score: r.Score, Depth: r.Depth, PV: r.PV}
+}
+
+// result creates the search result from the root principal variation
+func (w *searchWorker) result(score, depth int) SearchResult {
+	pv := make([]Move, w.pvLength[0])
+	copy(pv, w.pv[0][:w.pvLength[0]])
+
+	r := SearchResult{Score: score, PV: pv, Depth: depth, Nodes: w.nodes}
+	if len(pv) > 0 {
+		r.Move = pv[0]
+	}
+
+	return r
+}
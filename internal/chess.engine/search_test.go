@@ -0,0 +1,212 @@
+package chess_engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// minimax is a plain negamax without pruning, used to verify that
+// alpha-beta pruning doesn't change the score. The worker w runs
+// the quiescence search at the leaves.
+func minimax(w *searchWorker, b *Board, depth, ply int) int {
+	if ply > 0 && b.isDraw() {
+		return 0
+	}
+	if depth == 0 {
+		return w.quiescence(b, ply, -scoreInfinity, scoreInfinity)
+	}
+	moves := NewMover().GenerateLegalMoves(b)
+	if len(moves) == 0 {
+		if b.InCheck() {
+			return -scoreMate + ply
+		}
+		return 0
+	}
+	best := -scoreInfinity
+	for _, m := range moves {
+		if score := -minimax(w, b.makeMove(m), depth-1, ply+1); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+func TestSearch_SameScoreAsMinimax(t *testing.T) {
+	tests := []struct {
+		fen   string
+		depth int
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 3},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 2},
+		{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 2},
+	}
+	w := newSearchWorker(DefaultSearchConfig(), NewTranspositionTable(1))
+	for _, tt := range tests {
+		b, err := FromFEN(tt.fen)
+		assert.Nil(t, err)
+		for depth := 1; depth <= tt.depth; depth++ {
+			// Only the techniques that don't change the score
+			s := NewSearcher()
+			s.Config = SearchConfig{
+				HashMove:          true,
+				MVVLVA:            true,
+				KillerMoves:       true,
+				HistoryHeuristic:  true,
+				PVS:               true,
+				AspirationWindows: true,
+			}
+			r := s.Think(context.Background(), NewGame(b), SearchLimits{Depth: depth}, nil)
+			assert.Equal(t, minimax(w, b, depth, 0), r.Score, "%s depth %d", tt.fen, depth)
+		}
+	}
+}
+
+func TestSearch_MateInOne(t *testing.T) {
+	b, err := FromFEN("6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+	assert.Nil(t, err)
+
+	r := Search(b, 3)
+	assert.Equal(t, "a1a8", r.Move.String())
+	assert.Equal(t, scoreMate-1, r.Score)
+	assert.Len(t, r.PV, 1)
+}
+
+func TestSearch_CapturesHangingPiece(t *testing.T) {
+	b, err := FromFEN("4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1")
+	assert.Nil(t, err)
+
+	r := Search(b, 2)
+	assert.Equal(t, "d2d5", r.Move.String())
+	assert.Greater(t, r.Score, 0)
+	assert.Greater(t, r.Nodes, uint64(0))
+	assert.Equal(t, 2, r.Depth)
+}
+
+func TestSearch_PV(t *testing.T) {
+	b := NewBoard(true)
+
+	r := Search(b, 3)
+	assert.Len(t, r.PV, 3)
+	assert.Equal(t, r.Move, r.PV[0])
+
+	// The principal variation must be playable
+	for _, m := range r.PV {
+		var err error
+		b, err = b.MakeMove(m)
+		assert.Nil(t, err)
+	}
+}
+
+func TestSearch_NoLegalMoves(t *testing.T) {
+	// Checkmated
+	b, err := FromFEN("3R2k1/5ppp/8/8/8/8/8/6K1 b - - 0 1")
+	assert.Nil(t, err)
+	r := Search(b, 2)
+	assert.Equal(t, NoMove, r.Move)
+	assert.Equal(t, -scoreMate, r.Score)
+
+	// Stalemate
+	b, err = FromFEN("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	assert.Nil(t, err)
+	r = Search(b, 2)
+	assert.Equal(t, NoMove, r.Move)
+	assert.Equal(t, 0, r.Score)
+}
+
+func TestSearch_Quiescence(t *testing.T) {
+	// Taking the pawn on d5 looks good at depth 1,
+	// but the queen is lost to the recapture
+	b, err := FromFEN("4k3/8/2p1p3/3p4/8/8/8/3QK3 w - - 0 1")
+	assert.Nil(t, err)
+
+	r := Search(b, 1)
+	assert.NotEqual(t, "d1d5", r.Move.String())
+	assert.Greater(t, r.Score, 500)
+}
+
+func TestSearch_QuiescenceStandPat(t *testing.T) {
+	w := newSearchWorker(DefaultSearchConfig(), NewTranspositionTable(1))
+
+	// A quiet position is just evaluated
+	b := NewBoard(true)
+	assert.Equal(t, b.evaluate(), w.quiescence(b, 0, -scoreInfinity, scoreInfinity))
+
+	// The hanging queen is captured
+	b, err := FromFEN("4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1")
+	assert.Nil(t, err)
+	assert.Equal(t, b.makeMove(NewMove(Alg("d2"), Alg("d5"), PieceNone, MoveFlagCapture)).Value(), w.quiescence(b, 0, -scoreInfinity, scoreInfinity))
+}
+
+func TestSearch_Captures(t *testing.T) {
+	b, err := FromFEN("4k3/8/8/3q4/2P1p3/5Q2/8/4K3 w - - 0 1")
+	assert.Nil(t, err)
+
+	captures := newSearchWorker(DefaultSearchConfig(), NewTranspositionTable(1)).captures(b)
+	assert.Len(t, captures, 2)
+	assert.Equal(t, "c4d5", captures[0].String())
+	assert.Equal(t, "f3e4", captures[1].String())
+}
+
+func TestSearch_NullMoveCutoff(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want bool
+	}{
+		{"queen up", "4k3/8/8/8/8/8/8/3QK3 w - - 0 1", true},
+		{"pawn ending", "4k3/8/8/8/8/8/PPPP4/4K3 w - - 0 1", false},
+		{"below beta", "4k3/8/8/8/8/8/8/3QK3 b - - 0 1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			w := newSearchWorker(DefaultSearchConfig(), NewTranspositionTable(1))
+			assert.Equal(t, tt.want, w.nullMoveCutoff(b, b.evaluate(), 4, 1, 100, false))
+			assert.False(t, w.nullMoveCutoff(b, b.evaluate(), 4, 1, 100, true))
+		})
+	}
+}
+
+func TestSearch_SelectiveSearchFindsTactics(t *testing.T) {
+	configs := map[string]SearchConfig{
+		"null move":        {NullMove: true},
+		"lmr":              {LateMoveReductions: true},
+		"reverse futility": {ReverseFutility: true},
+		"futility":         {Futility: true},
+		"default":          DefaultSearchConfig(),
+	}
+	tests := []struct {
+		fen  string
+		move string
+	}{
+		{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "a1a8"},
+		{"4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1", "d2d5"},
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", "h5f7"},
+	}
+	for name, config := range configs {
+		for _, tt := range tests {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			s := NewSearcher()
+			s.Config = config
+			r := s.Think(context.Background(), NewGame(b), SearchLimits{Depth: 4}, nil)
+			assert.Equal(t, tt.move, r.Move.String(), "%s %s", name, tt.fen)
+		}
+	}
+}
+
+func TestSearch_CheckExtension(t *testing.T) {
+	// Black is checkmated, which is only seen by the
+	// quiescence search if the search is extended
+	b, err := FromFEN("R5k1/5ppp/8/8/8/8/8/6K1 b - - 1 1")
+	assert.Nil(t, err)
+
+	w := newSearchWorker(DefaultSearchConfig(), NewTranspositionTable(1))
+	assert.Equal(t, -scoreMate+1, w.negamax(b, 0, 1, -scoreInfinity, scoreInfinity))
+
+	w = newSearchWorker(SearchConfig{}, NewTranspositionTable(1))
+	assert.Equal(t, b.evaluate(), w.negamax(b, 0, 1, -scoreInfinity, scoreInfinity))
+}
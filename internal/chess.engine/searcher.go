@@ -0,0 +1,470 @@
+package chess_engine
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SearchLimits limits a search, zero values mean no limit
+//  - Depth          - maximum depth in plies
+//  - Nodes          - maximum number of nodes
+//  - MoveTime       - exact time to search
+//  - WhiteTime      - time left on white's clock
+//  - BlackTime      - time left on black's clock
+//  - WhiteIncrement - white's increment per move
+//  - BlackIncrement - black's increment per move
+//  - MovesToGo      - moves left to the next time control
+//  - Infinite       - search until cancelled, all other limits are ignored
+type SearchLimits struct {
+	Depth          int
+	Nodes          uint64
+	MoveTime       time.Duration
+	WhiteTime      time.Duration
+	BlackTime      time.Duration
+	WhiteIncrement time.Duration
+	BlackIncrement time.Duration
+	MovesToGo      int
+	Infinite       bool
+}
+
+// SearchInfo is reported after each completed depth, and when
+// the search at a depth fails outside of the aspiration window
+//  - PV         - the principal variation of the best line
+//  - Lines      - the best lines, best first, nil when the search failed high or low
+//  - Hashfull   - how full the transposition table is, in permille
+//  - LowerBound - the search failed high, the score is a lower bound
+//  - UpperBound - the search failed low, the score is an upper bound
+type SearchInfo struct {
+	Depth      int
+	Score      int
+	Nodes      uint64
+	NPS        uint64
+	Time       time.Duration
+	PV         []Move
+	Lines      []SearchLine
+	Hashfull   int
+	LowerBound bool
+	UpperBound bool
+}
+
+// Searcher searches for the best move using iterative deepening,
+// i e it searches to depth 1, 2, 3 and so on until a limit is reached :
+// https://www.chessprogramming.org/Iterative_Deepening
+//
+// With more than one thread, helper threads search the same board using
+// Lazy SMP, i e they share the transposition table with the main thread
+// and fill it with results that the main thread can use :
+// https://www.chessprogramming.org/Lazy_SMP
+type Searcher struct {
+	Config  SearchConfig
+	tt      *TranspositionTable
+	threads int
+	multiPV int
+}
+
+// SearchConfig turns search techniques on and off, so that
+// their effect can be measured
+//  - HashMove           - search the best move from the transposition table first
+//  - MVVLVA             - order captures by most valuable victim - least valuable attacker
+//  - KillerMoves        - search quiet moves that caused cutoffs at the same ply early
+//  - HistoryHeuristic   - order quiet moves by how often they have caused cutoffs
+//  - NullMove           - prune when passing still fails high in a reduced search
+//  - LateMoveReductions - search quiet moves late in the ordering less deep
+//  - ReverseFutility    - prune near the leaves when the evaluation is far above beta
+//  - Futility           - skip quiet moves near the leaves when the evaluation is far below alpha
+//  - PVS                - search all moves but the first with a zero window
+//  - AspirationWindows  - search with a narrow window around the score of the previous depth
+//  - CheckExtension     - search positions in check one ply deeper
+type SearchConfig struct {
+	HashMove           bool
+	MVVLVA             bool
+	KillerMoves        bool
+	HistoryHeuristic   bool
+	NullMove           bool
+	LateMoveReductions bool
+	ReverseFutility    bool
+	Futility           bool
+	PVS                bool
+	AspirationWindows  bool
+	CheckExtension     bool
+}
+
+// searchControl decides when a search has to stop
+//  - nodes       - the node limit, 0 if there is none
+//  - helperNodes - the number of nodes searched by the helper threads
+type searchControl struct {
+	ctx         context.Context
+	start       time.Time
+	deadline    time.Time
+	nodes       uint64
+	helperNodes uint64
+	stopped     int32
+}
+
+// defaultMovesToGo is the number of moves the remaining time is
+// divided between when there is no next time control
+const defaultMovesToGo = 30
+
+// moveOverhead is kept on the clock to cover communication delays
+const moveOverhead = 50 * time.Millisecond
+
+// Aspiration windows are used from aspirationDepth, and start
+// aspirationWindow centipawns on each side of the previous score
+const (
+	aspirationDepth  = 4
+	aspirationWindow = 25
+)
+
+// MaxThreads is the maximum number of search threads
+const MaxThreads = 256
+
+func NewSearcher() *Searcher {
+	return newSearcher(DefaultHashSize)
+}
+
+// DefaultSearchConfig returns the configuration with all search techniques turned on
+func DefaultSearchConfig() SearchConfig {
+	return SearchConfig{
+		HashMove:           true,
+		MVVLVA:             true,
+		KillerMoves:        true,
+		HistoryHeuristic:   true,
+		NullMove:           true,
+		LateMoveReductions: true,
+		ReverseFutility:    true,
+		Futility:           true,
+		PVS:                true,
+		AspirationWindows:  true,
+		CheckExtension:     true,
+	}
+}
+
+// SetHashSize replaces the transposition table with a new, empty, table of sizeMB MB
+func (s *Searcher) SetHashSize(sizeMB int) {
+	s.tt = NewTranspositionTable(sizeMB)
+}
+
+// SetMultiPV sets the number of best moves to search for, at least 1
+func (s *Searcher) SetMultiPV(n int) {
+	if n < 1 {
+		n = 1
+	}
+	s.multiPV = n
+}
+
+// MultiPV returns the number of best moves to search for
+func (s *Searcher) MultiPV() int {
+	return s.multiPV
+}
+
+// SetThreads sets the number of threads used to search, between 1 and MaxThreads
+func (s *Searcher) SetThreads(n int) {
+	if n < 1 {
+		n = 1
+	}
+	if n > MaxThreads {
+		n = MaxThreads
+	}
+	s.threads = n
+}
+
+// Threads returns the number of threads used to search
+func (s *Searcher) Threads() int {
+	return s.threads
+}
+
+// ClearHash removes all entries from the transposition table, i e
+// when a new game starts
+func (s *Searcher) ClearHash() {
+	s.tt.Clear()
+}
+
+// Think searches the current board of the game, until a limit is reached
+// or ctx is cancelled, and returns the result of the last completed depth.
+// The game history is used to detect repetitions. If report is not nil,
+// it is called after each completed depth.
+func (s *Searcher) Think(ctx context.Context, g *Game, limits SearchLimits, report func(SearchInfo)) SearchResult {
+	b := g.Board()
+	control := newSearchControl(ctx, limits, b.ToMove())
+
+	maxDepth := maxPly - 1
+	if limits.Depth > 0 && limits.Depth < maxDepth && !limits.Infinite {
+		maxDepth = limits.Depth
+	}
+
+	s.tt.NewSearch()
+	w := newSearchWorker(s.Config, s.tt)
+	w.control = control
+	w.hashes = g.Hashes()
+
+	// The helpers are stopped when the main thread is done
+	var wg sync.WaitGroup
+	for id := 1; id < s.threads; id++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			s.helper(id, b.Copy(), g.Hashes(), control, maxDepth)
+		}(id)
+	}
+	defer func() {
+		control.stop()
+		wg.Wait()
+	}()
+
+	// There can't be more lines than legal moves
+	lineCount := s.multiPV
+	if n := len(w.mover.GenerateLegalMoves(b)); n < lineCount {
+		lineCount = n
+	}
+
+	var result SearchResult
+	for depth := 1; depth <= maxDepth; depth++ {
+		r, ok := s.searchLines(w, b, depth, lineCount, result, report)
+		if !ok {
+			break
+		}
+
+		result = r
+		result.Nodes += control.helperCount()
+		if report != nil {
+			info := control.info(result)
+			info.Hashfull = s.tt.Hashfull()
+			report(info)
+		}
+
+		// Stop when there are no moves, or when there is
+		// not enough time left to finish the next depth
+		if result.Move == NoMove || control.isStopped() || control.outOfTime() {
+			break
+		}
+
+		// The first depth is always finished, so that there is a move to play
+		w.canStop = true
+	}
+
+	return result
+}
+
+// searchLines searches the board depth plies deep once for each line, where
+// the root moves of the earlier lines are excluded from each search. It
+// returns false if the search was stopped before all lines were searched.
+func (s *Searcher) searchLines(w *searchWorker, b *Board, depth, lineCount int, previous SearchResult, report func(SearchInfo)) (SearchResult, bool) {
+	w.excluded = w.excluded[:0]
+	defer func() { w.excluded = w.excluded[:0] }()
+
+	score := s.aspiration(w, b, depth, previous, report)
+	if depth > 1 && w.control.isStopped() {
+		return SearchResult{}, false
+	}
+	result := w.result(score, depth)
+	if result.Move == NoMove {
+		return result, true
+	}
+	result.Lines = append(result.Lines, result.line())
+
+	for i := 1; i < lineCount; i++ {
+		w.excluded = append(w.excluded, result.Lines[i-1].Move)
+		score := w.negamax(b, depth, 0, -scoreInfinity, scoreInfinity)
+		if w.stopped() {
+			return SearchResult{}, false
+		}
+		r := w.result(score, depth)
+		result.Lines = append(result.Lines, r.line())
+	}
+
+	// A later line can be better than an earlier one, when pruning
+	// or the transposition table gives different scores
+	sort.SliceStable(result.Lines, func(i, j int) bool {
+		return result.Lines[i].Score > result.Lines[j].Score
+	})
+	best := result.Lines[0]
+	result.Move, result.Score, result.PV = best.Move, best.Score, best.PV
+	result.Nodes = w.nodes
+
+	return result, true
+}
+
+// helper searches the board b in a helper thread, until the search is stopped.
+// Every other helper starts one depth deeper, so that the threads search
+// different depths at the same time, instead of the same positions.
+func (s *Searcher) helper(id int, b *Board, hashes []uint64, control *searchControl, maxDepth int) {
+	w := newSearchWorker(s.Config, s.tt)
+	w.control = control
+	w.hashes = hashes
+	w.helper = true
+	w.canStop = true
+
+	for depth := 1 + id%2; depth <= maxDepth && !control.isStopped(); depth++ {
+		w.negamax(b, depth, 0, -scoreInfinity, scoreInfinity)
+	}
+	atomic.AddUint64(&control.helperNodes, w.nodes%checkInterval)
+}
+
+// aspiration searches the board depth plies deep with a narrow window
+// around the score of the previous depth, and widens the window when
+// the score falls outside of it. Fail highs and fail lows are reported,
+// with the previous principal variation if the search failed low.
+// https://www.chessprogramming.org/Aspiration_Windows
+func (s *Searcher) aspiration(w *searchWorker, b *Board, depth int, previous SearchResult, report func(SearchInfo)) int {
+	if !s.Config.AspirationWindows || depth < aspirationDepth || isMateScore(previous.Score) {
+		return w.negamax(b, depth, 0, -scoreInfinity, scoreInfinity)
+	}
+
+	window := aspirationWindow
+	alpha, beta := previous.Score-window, previous.Score+window
+	for {
+		score := w.negamax(b, depth, 0, alpha, beta)
+		if w.stopped() {
+			return score
+		}
+
+		var info SearchInfo
+		switch {
+		case score <= alpha:
+			info = w.control.info(w.result(score, depth))
+			info.UpperBound = true
+			if len(info.PV) == 0 {
+				info.PV = previous.PV
+			}
+			alpha = score - window
+			if alpha < -scoreMate {
+				alpha = -scoreInfinity
+			}
+		case score >= beta:
+			info = w.control.info(w.result(score, depth))
+			info.LowerBound = true
+			beta = score + window
+			if beta > scoreMate {
+				beta = scoreInfinity
+			}
+		default:
+			return score
+		}
+
+		if report != nil {
+			info.Hashfull = s.tt.Hashfull()
+			report(info)
+		}
+		window *= 2
+	}
+}
+
+// TimeBudget returns the time to search for the color c to move,
+// or 0 if there is no time limit
+func (l SearchLimits) TimeBudget(c Color) time.Duration {
+	if l.Infinite {
+		return 0
+	}
+	if l.MoveTime > 0 {
+		return l.MoveTime
+	}
+
+	left, inc := l.WhiteTime, l.WhiteIncrement
+	if c == ColorBlack {
+		left, inc = l.BlackTime, l.BlackIncrement
+	}
+	if left <= 0 {
+		return 0
+	}
+
+	movesToGo := l.MovesToGo
+	if movesToGo <= 0 {
+		movesToGo = defaultMovesToGo
+	}
+
+	// Never use more than what is left on the clock
+	budget := left/time.Duration(movesToGo) + inc*3/4
+	max := left - moveOverhead
+	if max < left/10 {
+		max = left / 10
+	}
+	if budget > max {
+		budget = max
+	}
+
+	return budget
+}
+
+//
+// Private functions
+//
+
+// newSearcher creates a searcher with a transposition table of sizeMB MB
+func newSearcher(sizeMB int) *Searcher {
+	return &Searcher{Config: DefaultSearchConfig(), tt: NewTranspositionTable(sizeMB), threads: 1, multiPV: 1}
+}
+
+func newSearchControl(ctx context.Context, limits SearchLimits, c Color) *searchControl {
+	control := &searchControl{ctx: ctx, start: time.Now()}
+	if limits.Infinite {
+		return control
+	}
+
+	control.nodes = limits.Nodes
+	if budget := limits.TimeBudget(c); budget > 0 {
+		control.deadline = control.start.Add(budget)
+	}
+
+	return control
+}
+
+// check returns true if the search has to stop, because it has been
+// cancelled, the time is up or the node limit is reached
+func (c *searchControl) check(nodes uint64) bool {
+	if c.isStopped() {
+		return true
+	}
+
+	stop := false
+	select {
+	case <-c.ctx.Done():
+		stop = true
+	default:
+	}
+	if c.nodes > 0 && nodes >= c.nodes {
+		stop = true
+	}
+	if !c.deadline.IsZero() && time.Now().After(c.deadline) {
+		stop = true
+	}
+
+	if stop {
+		c.stop()
+	}
+	return stop
+}
+
+// stop stops the search
+func (c *searchControl) stop() {
+	atomic.StoreInt32(&c.stopped, 1)
+}
+
+// helperCount returns the number of nodes searched by the helper threads
+func (c *searchControl) helperCount() uint64 {
+	return atomic.LoadUint64(&c.helperNodes)
+}
+
+func (c *searchControl) isStopped() bool {
+	return atomic.LoadInt32(&c.stopped) != 0
+}
+
+// outOfTime returns true if more than half of the time has been used,
+// since the next depth would then most likely not be finished
+func (c *searchControl) outOfTime() bool {
+	if c.deadline.IsZero() {
+		return false
+	}
+	return time.Since(c.start) > c.deadline.Sub(c.start)/2
+}
+
+func (c *searchControl) info(r SearchResult) SearchInfo {
+	elapsed := time.Since(c.start)
+	info := SearchInfo{Depth: r.Depth, Score: r.Score, Nodes: r.Nodes, Time: elapsed, PV: r.PV, Lines: r.Lines}
+	if elapsed > 0 {
+		info.NPS = uint64(float64(r.Nodes) / elapsed.Seconds())
+	}
+	return info
+}
@@ -0,0 +1,263 @@
+package chess_engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearcher_ThinkDepth(t *testing.T) {
+	var infos []SearchInfo
+	report := func(info SearchInfo) {
+		if !info.LowerBound && !info.UpperBound {
+			infos = append(infos, info)
+		}
+	}
+
+	r := NewSearcher().Think(context.Background(), NewGame(NewBoard(true)), SearchLimits{Depth: 4}, report)
+	assert.Equal(t, 4, r.Depth)
+	assert.NotEqual(t, NoMove, r.Move)
+
+	assert.Len(t, infos, 4)
+	for i, info := range infos {
+		assert.Equal(t, i+1, info.Depth)
+		assert.Len(t, info.PV, i+1)
+		assert.Greater(t, info.Nodes, uint64(0))
+	}
+	assert.Equal(t, r.Nodes, infos[3].Nodes)
+}
+
+func TestSearcher_ThinkCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	r := NewSearcher().Think(ctx, NewGame(NewBoard(true)), SearchLimits{Infinite: true}, nil)
+	assert.Less(t, int64(time.Since(start)), int64(2*time.Second))
+	assert.NotEqual(t, NoMove, r.Move)
+}
+
+func TestSearcher_ThinkMoveTime(t *testing.T) {
+	start := time.Now()
+	r := NewSearcher().Think(context.Background(), NewGame(NewBoard(true)), SearchLimits{MoveTime: 100 * time.Millisecond}, nil)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	assert.NotEqual(t, NoMove, r.Move)
+}
+
+func TestSearcher_ThinkNodes(t *testing.T) {
+	r := NewSearcher().Think(context.Background(), NewGame(NewBoard(true)), SearchLimits{Nodes: 5000}, nil)
+	assert.NotEqual(t, NoMove, r.Move)
+	assert.Less(t, r.Nodes, uint64(5000))
+}
+
+func TestSearcher_ThinkRepetition(t *testing.T) {
+	// Black is a queen down, but can repeat a position from the game
+	b, err := FromFEN("7k/8/8/8/8/8/8/3QK3 b - - 0 1")
+	assert.Nil(t, err)
+	g := NewGame(b)
+	playMoves(t, g, "h8g8", "d1d2", "g8h8", "d2d1")
+
+	r := NewSearcher().Think(context.Background(), NewGame(g.Board()), SearchLimits{Depth: 3}, nil)
+	assert.Less(t, r.Score, -500)
+
+	r = NewSearcher().Think(context.Background(), g, SearchLimits{Depth: 3}, nil)
+	assert.Equal(t, 0, r.Score)
+	assert.Equal(t, "h8g8", r.Move.String())
+}
+
+func TestSearchLimits_TimeBudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits SearchLimits
+		color  Color
+		want   time.Duration
+	}{
+		{"No limits", SearchLimits{}, ColorWhite, 0},
+		{"Infinite", SearchLimits{Infinite: true, MoveTime: time.Second}, ColorWhite, 0},
+		{"Move time", SearchLimits{MoveTime: 3 * time.Second}, ColorBlack, 3 * time.Second},
+		{"White time", SearchLimits{WhiteTime: 60 * time.Second, BlackTime: 30 * time.Second}, ColorWhite, 2 * time.Second},
+		{"Black time", SearchLimits{WhiteTime: 60 * time.Second, BlackTime: 30 * time.Second}, ColorBlack, time.Second},
+		{"Increment", SearchLimits{WhiteTime: 60 * time.Second, WhiteIncrement: 4 * time.Second}, ColorWhite, 5 * time.Second},
+		{"Moves to go", SearchLimits{WhiteTime: 60 * time.Second, MovesToGo: 10}, ColorWhite, 6 * time.Second},
+		{"Low on time", SearchLimits{WhiteTime: time.Second, WhiteIncrement: 2 * time.Second}, ColorWhite, time.Second - moveOverhead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.limits.TimeBudget(tt.color))
+		})
+	}
+}
+
+func TestSearcher_Aspiration(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous int
+		lower    bool
+		upper    bool
+	}{
+		{"fail low", 500, false, true},
+		{"fail high", -500, true, false},
+		{"inside window", 0, false, false},
+	}
+	// Without pruning, the window doesn't change the score
+	config := SearchConfig{HashMove: true, MVVLVA: true, KillerMoves: true, PVS: true, AspirationWindows: true}
+	b := NewBoard(true)
+	want := newSearchWorker(config, NewTranspositionTable(1)).negamax(b, 4, 0, -scoreInfinity, scoreInfinity)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var infos []SearchInfo
+			report := func(info SearchInfo) {
+				infos = append(infos, info)
+			}
+
+			s := NewSearcher()
+			s.Config = config
+			w := newSearchWorker(s.Config, s.tt)
+			w.control = newSearchControl(context.Background(), SearchLimits{}, b.ToMove())
+			w.hashes = []uint64{b.Hash()}
+			previous := SearchResult{Score: want + tt.previous, PV: []Move{NewMove(Alg("e2"), Alg("e4"), PieceNone, 0)}}
+			assert.Equal(t, want, s.aspiration(w, b, 4, previous, report))
+			if !tt.lower && !tt.upper {
+				assert.Len(t, infos, 0)
+				return
+			}
+			assert.Greater(t, len(infos), 0)
+			assert.Equal(t, tt.lower, infos[0].LowerBound)
+			assert.Equal(t, tt.upper, infos[0].UpperBound)
+			assert.NotEmpty(t, infos[0].PV)
+		})
+	}
+}
+
+func TestSearcher_SetThreads(t *testing.T) {
+	s := NewSearcher()
+	assert.Equal(t, 1, s.Threads())
+	s.SetThreads(4)
+	assert.Equal(t, 4, s.Threads())
+	s.SetThreads(0)
+	assert.Equal(t, 1, s.Threads())
+	s.SetThreads(MaxThreads + 1)
+	assert.Equal(t, MaxThreads, s.Threads())
+}
+
+func TestSearcher_ThinkThreads(t *testing.T) {
+	b, err := FromFEN("r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4")
+	assert.Nil(t, err)
+
+	s := NewSearcher()
+	s.SetThreads(4)
+	r := s.Think(context.Background(), NewGame(b), SearchLimits{Depth: 4}, nil)
+	assert.Equal(t, "h5f7", r.Move.String())
+	assert.Equal(t, 4, r.Depth)
+}
+
+func TestSearcher_ThinkThreadsCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	s := NewSearcher()
+	s.SetThreads(4)
+	start := time.Now()
+	r := s.Think(ctx, NewGame(NewBoard(true)), SearchLimits{Infinite: true}, nil)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	assert.NotEqual(t, NoMove, r.Move)
+}
+
+// benchmarkThreads searches a fixed position to a fixed depth, the
+// time per search shows how the search scales with the number of threads
+func benchmarkThreads(b *testing.B, threads int) {
+	board, err := FromFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	nodes := uint64(0)
+	for i := 0; i < b.N; i++ {
+		s := NewSearcher()
+		s.SetThreads(threads)
+		nodes += s.Think(context.Background(), NewGame(board), SearchLimits{Depth: 7}, nil).Nodes
+	}
+	b.ReportMetric(float64(nodes)/float64(b.N), "nodes/op")
+}
+
+func BenchmarkSearcher_Threads1(b *testing.B) {
+	benchmarkThreads(b, 1)
+}
+
+func BenchmarkSearcher_Threads2(b *testing.B) {
+	benchmarkThreads(b, 2)
+}
+
+func BenchmarkSearcher_Threads4(b *testing.B) {
+	benchmarkThreads(b, 4)
+}
+
+func TestSearcher_MultiPV(t *testing.T) {
+	var infos []SearchInfo
+	report := func(info SearchInfo) {
+		if !info.LowerBound && !info.UpperBound {
+			infos = append(infos, info)
+		}
+	}
+
+	s := NewSearcher()
+	s.SetMultiPV(3)
+	assert.Equal(t, 3, s.MultiPV())
+	r := s.Think(context.Background(), NewGame(NewBoard(true)), SearchLimits{Depth: 3}, report)
+
+	assert.Len(t, r.Lines, 3)
+	assert.Equal(t, r.Move, r.Lines[0].Move)
+	assert.Equal(t, r.Score, r.Lines[0].Score)
+	moves := map[Move]bool{}
+	for i, line := range r.Lines {
+		assert.Equal(t, 3, line.Depth)
+		assert.Equal(t, line.Move, line.PV[0])
+		assert.False(t, moves[line.Move], "duplicate move %s", line.Move)
+		moves[line.Move] = true
+		if i > 0 {
+			assert.LessOrEqual(t, line.Score, r.Lines[i-1].Score)
+		}
+	}
+
+	assert.Len(t, infos, 3)
+	for _, info := range infos {
+		assert.Len(t, info.Lines, 3)
+	}
+}
+
+func TestSearcher_MultiPVNodes(t *testing.T) {
+	// The first line is searched the same way, so the nodes
+	// of the other lines are added to the count
+	nodes := func(multiPV int) uint64 {
+		s := NewSearcher()
+		s.SetMultiPV(multiPV)
+		return s.Think(context.Background(), NewGame(NewBoard(true)), SearchLimits{Depth: 1}, nil).Nodes
+	}
+	assert.Greater(t, nodes(3), nodes(1))
+}
+
+func TestSearcher_MultiPVFewMoves(t *testing.T) {
+	// The king has one move
+	b, err := FromFEN("7k/R7/8/8/8/8/8/K7 b - - 0 1")
+	assert.Nil(t, err)
+
+	s := NewSearcher()
+	s.SetMultiPV(5)
+	r := s.Think(context.Background(), NewGame(b), SearchLimits{Depth: 3}, nil)
+	assert.Len(t, r.Lines, 1)
+	assert.Equal(t, "h8g8", r.Move.String())
+}
+
+func TestSearcher_MultiPVNoMoves(t *testing.T) {
+	b, err := FromFEN("R5k1/5ppp/8/8/8/8/8/6K1 b - - 1 1")
+	assert.Nil(t, err)
+
+	s := NewSearcher()
+	s.SetMultiPV(3)
+	r := s.Think(context.Background(), NewGame(b), SearchLimits{Depth: 3}, nil)
+	assert.Equal(t, NoMove, r.Move)
+	assert.Len(t, r.Lines, 0)
+}
@@ -0,0 +1,137 @@
+package chess_engine
+
+type GameStatus int
+
+const (
+	StatusOngoing GameStatus = iota
+	StatusCheckmate
+	StatusStalemate
+	StatusFiftyMoveRule
+	StatusInsufficientMaterial
+	StatusThreefoldRepetition
+)
+
+// String returns a description of the status
+func (s GameStatus) String() string {
+	switch s {
+	case StatusOngoing:
+		return "Ongoing"
+	case StatusCheckmate:
+		return "Checkmate"
+	case StatusStalemate:
+		return "Stalemate"
+	case StatusFiftyMoveRule:
+		return "Draw by the fifty-move rule"
+	case StatusInsufficientMaterial:
+		return "Draw by insufficient material"
+	case StatusThreefoldRepetition:
+		return "Draw by threefold repetition"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsOver returns true if the game has ended
+func (s GameStatus) IsOver() bool {
+	return s != StatusOngoing
+}
+
+// IsDraw returns true if the game has ended in a draw
+func (s GameStatus) IsDraw() bool {
+	return s.IsOver() && s != StatusCheckmate
+}
+
+// Status returns the status of the game at this board. Threefold
+// repetition can't be detected from a single board, use
+// StatusWithHistory for that.
+func (b *Board) Status() GameStatus {
+	if len(NewMover().GenerateLegalMoves(b)) == 0 {
+		if b.InCheck() {
+			return StatusCheckmate
+		}
+		return StatusStalemate
+	}
+	if b.HalfMoveCount() >= 100 {
+		return StatusFiftyMoveRule
+	}
+	if b.isInsufficientMaterial() {
+		return StatusInsufficientMaterial
+	}
+
+	return StatusOngoing
+}
+
+// StatusWithHistory returns the status of the game at this board, where
+// history contains the boards of the game before this board
+func (b *Board) StatusWithHistory(history []*Board) GameStatus {
+	if s := b.Status(); s.IsOver() {
+		return s
+	}
+
+	count := 1
+	for _, h := range history {
+		if b.samePosition(h) {
+			count++
+		}
+	}
+	if count >= 3 {
+		return StatusThreefoldRepetition
+	}
+
+	return StatusOngoing
+}
+
+//
+// Private functions
+//
+
+// isDraw returns true if the game is drawn by the fifty-move
+// rule or by insufficient material
+func (b *Board) isDraw() bool {
+	return b.HalfMoveCount() >= 100 || b.isInsufficientMaterial()
+}
+
+// samePosition returns true if the boards have the same pieces, color
+// to move, castling rights and en passant target, the move counters
+// are not compared
+func (b *Board) samePosition(o *Board) bool {
+	const positionMask = 0b00000000_00000001_11111111_00000001
+
+	if b.hash != o.hash || b.board != o.board {
+		return false
+	}
+	return b.extra&positionMask == o.extra&positionMask
+}
+
+// isInsufficientMaterial returns true when neither side can checkmate, i e
+//    1. King against king
+//    2. King and a bishop or a knight against king
+//    3. Kings and bishops only, with all bishops on the same square color
+func (b *Board) isInsufficientMaterial() bool {
+	var minors, knights int
+	var lightBishops, darkBishops int
+
+	for i := 0; i < 64; i++ {
+		switch getPieceType(b.Piece(Pos(i))) {
+		case PieceNone, PieceWhiteKing:
+		case PieceWhiteKnight:
+			minors++
+			knights++
+		case PieceWhiteBishop:
+			minors++
+			if x, y := Pos(i).ToXY(); (x+y)%2 == 0 {
+				darkBishops++
+			} else {
+				lightBishops++
+			}
+		default:
+			// Pawns, rooks and queens can always mate
+			return false
+		}
+	}
+
+	if minors <= 1 {
+		return true
+	}
+	return knights == 0 && (lightBishops == 0 || darkBishops == 0)
+}
@@ -0,0 +1,70 @@
+package chess_engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoard_Status(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want GameStatus
+	}{
+		{"Initial position", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", StatusOngoing},
+		{"Fool's mate", "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", StatusCheckmate},
+		{"Back rank mate", "3R2k1/5ppp/8/8/8/8/8/6K1 b - - 0 1", StatusCheckmate},
+		{"Stalemate", "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", StatusStalemate},
+		{"Fifty-move rule", "4k3/8/8/8/8/8/4R3/4K3 w - - 100 80", StatusFiftyMoveRule},
+		{"Checkmate beats fifty-move rule", "3R2k1/5ppp/8/8/8/8/8/6K1 b - - 100 80", StatusCheckmate},
+		{"King against king", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", StatusInsufficientMaterial},
+		{"King and knight", "4k3/8/8/8/8/8/8/4KN2 w - - 0 1", StatusInsufficientMaterial},
+		{"King and bishop", "4k3/8/8/8/8/8/8/4KB2 w - - 0 1", StatusInsufficientMaterial},
+		{"Bishops on the same color", "4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", StatusInsufficientMaterial},
+		{"Bishops on different colors", "4k1b1/8/8/8/8/8/8/2B1K3 w - - 0 1", StatusOngoing},
+		{"Two knights", "4k3/8/8/8/8/8/8/3NKN2 w - - 0 1", StatusOngoing},
+		{"Pawn", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", StatusOngoing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := FromFEN(tt.fen)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, b.Status())
+		})
+	}
+}
+
+func TestBoard_StatusWithHistory(t *testing.T) {
+	b := NewBoard(true)
+	history := []*Board{}
+
+	moves := []string{"g1f3", "g8f6", "f3g1", "f6g8", "g1f3", "g8f6", "f3g1", "f6g8"}
+	for i, m := range moves {
+		history = append(history, b)
+		b = b.MovePiece(Alg(m[0:2]), Alg(m[2:4]))
+		if i < len(moves)-1 {
+			assert.Equal(t, StatusOngoing, b.StatusWithHistory(history), "after %s", m)
+		}
+	}
+	assert.Equal(t, StatusThreefoldRepetition, b.StatusWithHistory(history))
+	assert.Equal(t, StatusOngoing, b.Status())
+}
+
+func TestBoard_HalfMoveCountOverflow(t *testing.T) {
+	b, err := FromFEN("4k3/8/8/8/8/8/4R3/4K3 w - - 127 80")
+	assert.Nil(t, err)
+
+	b = b.MovePiece(Alg("e2"), Alg("d2"))
+	assert.Equal(t, 127, b.HalfMoveCount())
+	assert.Equal(t, false, b.CastlingRights(CastlingWhiteKing))
+}
+
+func TestGameStatus(t *testing.T) {
+	assert.False(t, StatusOngoing.IsOver())
+	assert.True(t, StatusCheckmate.IsOver())
+	assert.False(t, StatusCheckmate.IsDraw())
+	assert.True(t, StatusStalemate.IsDraw())
+	assert.True(t, StatusThreefoldRepetition.IsDraw())
+	assert.Equal(t, "Checkmate", StatusCheckmate.String())
+}
@@ -0,0 +1,203 @@
+package chess_engine
+
+import (
+	"sync/atomic"
+)
+
+// TranspositionTable stores the results of earlier searches, so that
+// positions that are reached again (by a different move order or in a
+// later search) don't have to be searched again :
+// https://www.chessprogramming.org/Transposition_Table
+//
+// The table has a fixed size, and each bucket holds two entries, one that
+// is only replaced by deeper searches (or entries from older searches),
+// and one that is always replaced. Entries are written without locks, the
+// key is stored xor:ed with the data, so a torn write from another
+// goroutine is detected as a miss when probing.
+type TranspositionTable struct {
+	buckets []ttBucket
+	age     uint32
+}
+
+type ttBucket struct {
+	entries [2]ttEntry
+}
+
+// ttEntry is a transposition table entry
+//  - key  - the board hash xor:ed with data
+//  - data - bit 0-19  - best move
+//         - bit 20-35 - score
+//         - bit 36-43 - depth
+//         - bit 44-45 - bound
+//         - bit 46-53 - age
+type ttEntry struct {
+	key  uint64
+	data uint64
+}
+
+// ttData is the unpacked data of an entry
+type ttData struct {
+	move  Move
+	score int
+	depth int
+	bound ttBound
+}
+
+type ttBound uint8
+
+const (
+	ttBoundNone ttBound = iota
+	ttBoundExact
+	ttBoundLower
+	ttBoundUpper
+)
+
+const (
+	// DefaultHashSize is the default transposition table size in MB
+	DefaultHashSize = 16
+
+	ttBucketSize = 32 // bytes, two entries of two uint64
+)
+
+// NewTranspositionTable creates a transposition table that uses sizeMB MB of memory
+func NewTranspositionTable(sizeMB int) *TranspositionTable {
+	if sizeMB < 1 {
+		sizeMB = 1
+	}
+
+	return &TranspositionTable{buckets: make([]ttBucket, sizeMB*1024*1024/ttBucketSize)}
+}
+
+// Size returns the size of the table in MB
+func (t *TranspositionTable) Size() int {
+	return len(t.buckets) * ttBucketSize / (1024 * 1024)
+}
+
+// Clear removes all entries
+func (t *TranspositionTable) Clear() {
+	for i := range t.buckets {
+		t.buckets[i] = ttBucket{}
+	}
+	t.age = 0
+}
+
+// NewSearch is called before each search, so that
+// entries from earlier searches are replaced first
+func (t *TranspositionTable) NewSearch() {
+	t.age = (t.age + 1) & 0xff
+}
+
+// Hashfull returns how full the table is in permille, based
+// on the number of entries from the current search
+func (t *TranspositionTable) Hashfull() int {
+	n := 500
+	if n > len(t.buckets) {
+		n = len(t.buckets)
+	}
+
+	used := 0
+	for i := 0; i < n; i++ {
+		for j := range t.buckets[i].entries {
+			data := atomic.LoadUint64(&t.buckets[i].entries[j].data)
+			if data != 0 && uint32(data>>46&0xff) == t.age {
+				used++
+			}
+		}
+	}
+
+	return used * 1000 / (2 * n)
+}
+
+//
+// Private functions
+//
+
+// probe returns the entry for the board with the given hash. Mate scores
+// are stored relative to the position, and are adjusted to be relative
+// to the root, where the position is ply plies from the root.
+func (t *TranspositionTable) probe(hash uint64, ply int) (ttData, bool) {
+	b := &t.buckets[hash%uint64(len(t.buckets))]
+
+	for i := range b.entries {
+		e := &b.entries[i]
+		key, data := atomic.LoadUint64(&e.key), atomic.LoadUint64(&e.data)
+		if data == 0 || key^data != hash {
+			continue
+		}
+
+		d := unpackTTData(data)
+		d.score = scoreFromTT(d.score, ply)
+		return d, true
+	}
+
+	return ttData{}, false
+}
+
+// store saves the result of a search of the board with the given hash
+func (t *TranspositionTable) store(hash uint64, ply int, move Move, score, depth int, bound ttBound) {
+	b := &t.buckets[hash%uint64(len(t.buckets))]
+	data := packTTData(move, scoreToTT(score, ply), depth, bound, t.age)
+
+	// The depth-preferred entry is replaced by deeper searches, by
+	// the same position, and by anything if it is from an old search
+	e := &b.entries[0]
+	old := atomic.LoadUint64(&e.data)
+	oldKey := atomic.LoadUint64(&e.key) ^ old
+	if old == 0 || oldKey == hash || depth >= int(old>>36&0xff) || uint32(old>>46&0xff) != t.age {
+		// Keep the best move if there is no new one
+		if move == NoMove && oldKey == hash {
+			data |= old & 0xfffff
+		}
+		atomic.StoreUint64(&e.data, data)
+		atomic.StoreUint64(&e.key, hash^data)
+		return
+	}
+
+	e = &b.entries[1]
+	atomic.StoreUint64(&e.data, data)
+	atomic.StoreUint64(&e.key, hash^data)
+}
+
+func packTTData(move Move, score, depth int, bound ttBound, age uint32) uint64 {
+	if depth < 0 {
+		depth = 0
+	}
+	return uint64(move)&0xfffff |
+		uint64(uint16(int16(score)))<<20 |
+		uint64(depth&0xff)<<36 |
+		uint64(bound)<<44 |
+		uint64(age&0xff)<<46
+}
+
+func unpackTTData(data uint64) ttData {
+	return ttData{
+		move:  Move(data & 0xfffff),
+		score: int(int16(uint16(data >> 20))),
+		depth: int(data >> 36 & 0xff),
+		bound: ttBound(data >> 44 & 0b11),
+	}
+}
+
+// scoreToTT converts a mate score relative to the root, to
+// a score relative to the position ply plies from the root
+func scoreToTT(score, ply int) int {
+	if score > scoreMate-maxPly {
+		return score + ply
+	}
+	if score < -scoreMate+maxPly {
+		return score - ply
+	}
+	return score
+}
+
+// scoreFromTT converts a mate score relative to the position
+// ply plies from the root, to a score relative to the root
+func scoreFromTT(score, ply int) int {
+	if score > scoreMate-maxPly {
+		return score - ply
+	}
+	if score < -scoreMate+maxPly {
+		return score + ply
+	}
+	return score
+}
@@ -0,0 +1,158 @@
+package chess_engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranspositionTable_StoreProbe(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	assert.Equal(t, 1, tt.Size())
+
+	b := NewBoard(true)
+	m := NewMove(Alg("e2"), Alg("e4"), PieceNone, MoveFlagDoublePush)
+
+	_, ok := tt.probe(b.Hash(), 0)
+	assert.False(t, ok)
+
+	tt.store(b.Hash(), 0, m, -35, 7, ttBoundLower)
+	e, ok := tt.probe(b.Hash(), 0)
+	assert.True(t, ok)
+	assert.Equal(t, ttData{move: m, score: -35, depth: 7, bound: ttBoundLower}, e)
+
+	// A different position in the same bucket is a miss
+	_, ok = tt.probe(b.Hash()+uint64(len(tt.buckets)), 0)
+	assert.False(t, ok)
+
+	tt.Clear()
+	_, ok = tt.probe(b.Hash(), 0)
+	assert.False(t, ok)
+}
+
+func TestTranspositionTable_Promotion(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	m := NewMove(Alg("a7"), Alg("b8"), PieceBlackKnight, MoveFlagCapture)
+
+	tt.store(12345, 0, m, 0, 1, ttBoundExact)
+	e, ok := tt.probe(12345, 0)
+	assert.True(t, ok)
+	assert.Equal(t, m, e.move)
+}
+
+func TestTranspositionTable_MateScore(t *testing.T) {
+	tt := NewTranspositionTable(1)
+
+	// A mate in 5 plies from the root, found 3 plies from the root, is
+	// a mate in 2 plies from the position, and a mate in 7 plies from the
+	// root when the position is found 5 plies from the root
+	tt.store(1, 3, NoMove, scoreMate-5, 4, ttBoundExact)
+	e, ok := tt.probe(1, 5)
+	assert.True(t, ok)
+	assert.Equal(t, scoreMate-7, e.score)
+
+	tt.store(2, 3, NoMove, -scoreMate+5, 4, ttBoundExact)
+	e, ok = tt.probe(2, 5)
+	assert.True(t, ok)
+	assert.Equal(t, -scoreMate+7, e.score)
+
+	// Normal scores are not adjusted
+	tt.store(3, 3, NoMove, 250, 4, ttBoundExact)
+	e, _ = tt.probe(3, 5)
+	assert.Equal(t, 250, e.score)
+}
+
+func TestTranspositionTable_Replacement(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	n := uint64(len(tt.buckets))
+
+	// Three positions in the same bucket
+	a, b, c := uint64(5), 5+n, 5+2*n
+
+	tt.store(a, 0, NoMove, 1, 8, ttBoundExact)
+	tt.store(b, 0, NoMove, 2, 3, ttBoundExact)
+
+	// The deep entry is kept, the shallow one goes to the always-replace entry
+	_, ok := tt.probe(a, 0)
+	assert.True(t, ok)
+	_, ok = tt.probe(b, 0)
+	assert.True(t, ok)
+
+	// The always-replace entry is replaced
+	tt.store(c, 0, NoMove, 3, 2, ttBoundExact)
+	_, ok = tt.probe(a, 0)
+	assert.True(t, ok)
+	_, ok = tt.probe(b, 0)
+	assert.False(t, ok)
+
+	// Entries from an older search are replaced, even by shallower searches
+	tt.NewSearch()
+	tt.store(b, 0, NoMove, 2, 1, ttBoundExact)
+	_, ok = tt.probe(a, 0)
+	assert.False(t, ok)
+	_, ok = tt.probe(b, 0)
+	assert.True(t, ok)
+}
+
+func TestTranspositionTable_KeepsMove(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	m := NewMove(Alg("g1"), Alg("f3"), PieceNone, 0)
+
+	tt.store(42, 0, m, 10, 3, ttBoundLower)
+	tt.store(42, 0, NoMove, -10, 4, ttBoundUpper)
+
+	e, ok := tt.probe(42, 0)
+	assert.True(t, ok)
+	assert.Equal(t, m, e.move)
+	assert.Equal(t, ttBoundUpper, e.bound)
+	assert.Equal(t, 4, e.depth)
+}
+
+func TestTranspositionTable_Hashfull(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	assert.Equal(t, 0, tt.Hashfull())
+
+	for i := uint64(0); i < 250; i++ {
+		tt.store(i, 0, NoMove, 0, 1, ttBoundExact)
+	}
+	assert.Equal(t, 250, tt.Hashfull())
+
+	// Entries from older searches don't count
+	tt.NewSearch()
+	assert.Equal(t, 0, tt.Hashfull())
+}
+
+func TestTranspositionTable_Concurrent(t *testing.T) {
+	tt := NewTranspositionTable(1)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := uint64(0); i < 10000; i++ {
+				hash := i * 0x9e3779b97f4a7c15
+				tt.store(hash, 0, NoMove, int(i%1000), g+1, ttBoundExact)
+				if e, ok := tt.probe(hash, 0); ok {
+					assert.Equal(t, int(i%1000), e.score)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestSearcher_ThinkUsesHash(t *testing.T) {
+	s := NewSearcher()
+	g := NewGame(NewBoard(true))
+
+	first := s.Think(context.Background(), g, SearchLimits{Depth: 5}, nil)
+	second := s.Think(context.Background(), g, SearchLimits{Depth: 5}, nil)
+	assert.Less(t, second.Nodes, first.Nodes)
+
+	s.ClearHash()
+	third := s.Think(context.Background(), g, SearchLimits{Depth: 5}, nil)
+	assert.Equal(t, first.Nodes, third.Nodes)
+}
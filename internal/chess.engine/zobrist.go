@@ -0,0 +1,82 @@
+package chess_engine
+
+// Zobrist hashing : https://www.chessprogramming.org/Zobrist_Hashing
+//
+// The hash of a board is the xor of one random key for each piece on
+// its square, and keys for the color to move, the castling rights and
+// the en passant file. Keys are generated from a fixed seed, so hashes
+// are the same between runs.
+var (
+	zobristPieces    [16][64]uint64
+	zobristBlack     uint64
+	zobristCastling  [4]uint64
+	zobristEnPassant [8]uint64
+)
+
+func init() {
+	r := zobristRandom(0x9e3779b97f4a7c15)
+
+	for p := PieceWhitePawn; p <= PieceBlackKing; p++ {
+		if getPieceType(p) == PieceNone || getPieceType(p) > PieceWhiteKing {
+			continue
+		}
+		for i := 0; i < 64; i++ {
+			zobristPieces[p][i] = r.next()
+		}
+	}
+	zobristBlack = r.next()
+	for i := range zobristCastling {
+		zobristCastling[i] = r.next()
+	}
+	for i := range zobristEnPassant {
+		zobristEnPassant[i] = r.next()
+	}
+}
+
+// Hash returns the Zobrist hash of the board
+func (b *Board) Hash() uint64 {
+	return b.hash
+}
+
+//
+// Private functions
+//
+
+// computeHash calculates the hash of the board from scratch
+func (b *Board) computeHash() uint64 {
+	var h uint64
+	for i := 0; i < 64; i++ {
+		h ^= zobristPieces[b.Piece(Pos(i))][i]
+	}
+
+	return h ^ zobristExtra(b.extra)
+}
+
+// zobristExtra returns the part of the hash that depends on the extra
+// information, i e the color to move, castling rights and en passant file
+func zobristExtra(extra uint32) uint64 {
+	var h uint64
+	if extra&1 != 0 {
+		h ^= zobristBlack
+	}
+	for i := 0; i < 4; i++ {
+		if extra&(1<<(8+i)) != 0 {
+			h ^= zobristCastling[i]
+		}
+	}
+	if t := (extra >> 12) & 0b11111; t != 0 {
+		h ^= zobristEnPassant[(t-1)%8]
+	}
+
+	return h
+}
+
+// zobristRandom is a xorshift64* pseudo random number generator
+type zobristRandom uint64
+
+func (r *zobristRandom) next() uint64 {
+	*r ^= *r >> 12
+	*r ^= *r << 25
+	*r ^= *r >> 27
+	return uint64(*r) * 2685821657736338717
+}
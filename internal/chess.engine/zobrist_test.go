@@ -0,0 +1,89 @@
+package chess_engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkHashes walks the move tree and makes sure that the incrementally
+// updated hash is the same as the hash calculated from scratch
+func checkHashes(t *testing.T, b *Board, depth int) {
+	assert.Equal(t, b.computeHash(), b.Hash(), b.ToFEN())
+	if depth == 0 {
+		return
+	}
+	for _, m := range NewMover().GenerateLegalMoves(b) {
+		checkHashes(t, b.makeMove(m), depth-1)
+	}
+}
+
+func TestBoard_HashIncremental(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	}
+	for _, fen := range fens {
+		b, err := FromFEN(fen)
+		assert.Nil(t, err)
+		checkHashes(t, b, 2)
+	}
+}
+
+func TestBoard_HashTransposition(t *testing.T) {
+	b1 := NewBoard(true)
+	b1 = b1.MovePiece(Alg("g1"), Alg("f3"))
+	b1 = b1.MovePiece(Alg("g8"), Alg("f6"))
+	b1 = b1.MovePiece(Alg("b1"), Alg("c3"))
+
+	b2 := NewBoard(true)
+	b2 = b2.MovePiece(Alg("b1"), Alg("c3"))
+	b2 = b2.MovePiece(Alg("g8"), Alg("f6"))
+	b2 = b2.MovePiece(Alg("g1"), Alg("f3"))
+
+	assert.Equal(t, b1.Hash(), b2.Hash())
+
+	b3, err := FromFEN(b1.ToFEN())
+	assert.Nil(t, err)
+	assert.Equal(t, b1.Hash(), b3.Hash())
+}
+
+func TestBoard_HashDiffers(t *testing.T) {
+	b := NewBoard(true)
+	assert.NotEqual(t, uint64(0), b.Hash())
+
+	// Color to move
+	w, _ := FromFEN("4k3/8/8/8/8/8/8/4K3 w - - 0 1")
+	bl, _ := FromFEN("4k3/8/8/8/8/8/8/4K3 b - - 0 1")
+	assert.NotEqual(t, w.Hash(), bl.Hash())
+
+	// Castling rights
+	c1, _ := FromFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	c2, _ := FromFEN("r3k2r/8/8/8/8/8/8/R3K2R w Kkq - 0 1")
+	assert.NotEqual(t, c1.Hash(), c2.Hash())
+
+	// En passant
+	e1, _ := FromFEN("4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2")
+	e2, _ := FromFEN("4k3/8/8/3pP3/8/8/8/4K3 w - - 0 2")
+	assert.NotEqual(t, e1.Hash(), e2.Hash())
+
+	// Move counters are not part of the hash
+	m1, _ := FromFEN("4k3/8/8/8/8/8/8/4K3 w - - 0 1")
+	m2, _ := FromFEN("4k3/8/8/8/8/8/8/4K3 w - - 12 40")
+	assert.Equal(t, m1.Hash(), m2.Hash())
+}
+
+func TestGame_Hashes(t *testing.T) {
+	g := NewGame(NewBoard(true))
+	playMoves(t, g, "g1f3", "g8f6", "f3g1", "f6g8")
+
+	hashes := g.Hashes()
+	assert.Len(t, hashes, 5)
+	assert.Equal(t, hashes[0], hashes[4])
+	assert.NotEqual(t, hashes[0], hashes[2])
+
+	g.Undo()
+	assert.Len(t, g.Hashes(), 4)
+}
@@ -0,0 +1,440 @@
+package chess_uci
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	chess "github.com/hultan/chess/internal/chess.engine"
+)
+
+const (
+	engineName   = "chess"
+	engineAuthor = "SoftTeam AB"
+
+	maxHashSize = 4096
+	maxMultiPV  = 256
+)
+
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// Engine speaks the UCI protocol, reading commands from in and
+// writing responses to out : https://www.wbec-ridderkerk.nl/html/UCIProtocol.html
+type Engine struct {
+	in       io.Reader
+	out      io.Writer
+	outLock  sync.Mutex
+	searcher *chess.Searcher
+	game     *chess.Game
+	search   *search
+}
+
+// search is a running search
+//  - cancel   - stops the search
+//  - done     - closed when the best move has been written
+//  - release  - closed when the best move may be written, in ponder or infinite mode
+//  - released - true if release has been closed
+//  - limits   - the limits of the go command, used when a ponder search becomes a normal one
+type search struct {
+	cancel   context.CancelFunc
+	done     chan struct{}
+	release  chan struct{}
+	released bool
+	limits   chess.SearchLimits
+	color    chess.Color
+}
+
+// New creates a new UCI engine
+func New(in io.Reader, out io.Writer) *Engine {
+	return &Engine{
+		in:       in,
+		out:      out,
+		searcher: chess.NewSearcher(),
+		game:     chess.NewGame(chess.NewBoard(true)),
+	}
+}
+
+// Run reads and executes commands until the quit command is received or
+// the input ends. When the input ends, a running search with limits is
+// finished first, so that commands can be piped to the engine.
+func (e *Engine) Run() error {
+	scanner := bufio.NewScanner(e.in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" {
+			e.stop()
+			return nil
+		}
+		e.execute(fields[0], fields[1:])
+	}
+
+	if s := e.search; s != nil && s.released {
+		<-s.done
+	}
+	e.stop()
+
+	return scanner.Err()
+}
+
+//
+// Private functions
+//
+
+func (e *Engine) execute(command string, args []string) {
+	switch command {
+	case "uci":
+		e.uci()
+	case "isready":
+		e.writeLine("readyok")
+	case "ucinewgame":
+		e.stop()
+		e.searcher.ClearHash()
+		e.game = chess.NewGame(chess.NewBoard(true))
+	case "position":
+		e.stop()
+		e.position(args)
+	case "go":
+		e.stop()
+		e.goSearch(args)
+	case "stop":
+		e.stop()
+	case "ponderhit":
+		e.ponderHit()
+	case "setoption":
+		e.setOption(args)
+	case "debug", "register":
+		// Not supported
+	default:
+		e.writeLine("info string unknown command " + command)
+	}
+}
+
+func (e *Engine) uci() {
+	e.writeLine("id name " + engineName)
+	e.writeLine("id author " + engineAuthor)
+	e.writeLine(fmt.Sprintf("option name Hash type spin default %d min 1 max %d", chess.DefaultHashSize, maxHashSize))
+	e.writeLine(fmt.Sprintf("option name Threads type spin default 1 min 1 max %d", chess.MaxThreads))
+	e.writeLine(fmt.Sprintf("option name MultiPV type spin default 1 min 1 max %d", maxMultiPV))
+	e.writeLine("option name Ponder type check default false")
+	e.writeLine("uciok")
+}
+
+// position sets up the board : position [startpos | fen <fen>] [moves <move1> ... <moveN>]
+func (e *Engine) position(args []string) {
+	if len(args) == 0 {
+		e.writeLine("info string missing position")
+		return
+	}
+
+	fen, rest := startFEN, args[1:]
+	switch args[0] {
+	case "startpos":
+	case "fen":
+		i := indexOf(args, "moves")
+		if i < 0 {
+			i = len(args)
+		}
+		fen, rest = strings.Join(args[1:i], " "), args[i:]
+	default:
+		e.writeLine("info string invalid position " + args[0])
+		return
+	}
+
+	b, err := chess.FromFEN(fen)
+	if err != nil {
+		e.writeLine("info string invalid fen : " + err.Error())
+		return
+	}
+	g := chess.NewGame(b)
+
+	if len(rest) > 0 && rest[0] == "moves" {
+		for _, s := range rest[1:] {
+			m, err := chess.ParseMove(s)
+			if err == nil {
+				err = g.Play(m)
+			}
+			if err != nil {
+				e.writeLine("info string invalid move " + s + " : " + err.Error())
+				return
+			}
+		}
+	}
+
+	e.game = g
+}
+
+// goSearch starts a search : go [wtime <ms>] [btime <ms>] [winc <ms>] [binc <ms>]
+// [movestogo <n>] [depth <n>] [nodes <n>] [mate <n>] [movetime <ms>] [infinite] [ponder]
+func (e *Engine) goSearch(args []string) {
+	var limits chess.SearchLimits
+	ponder, mate := false, 0
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "infinite":
+			limits.Infinite = true
+			continue
+		case "ponder":
+			ponder = true
+			continue
+		case "searchmoves":
+			// Not supported, the moves are skipped
+			for i+1 < len(args) && isMove(args[i+1]) {
+				i++
+			}
+			continue
+		}
+
+		if i+1 >= len(args) {
+			break
+		}
+		n, err := strconv.ParseInt(args[i+1], 10, 64)
+		if err != nil {
+			e.writeLine("info string invalid value " + args[i+1] + " for " + args[i])
+			continue
+		}
+		i++
+
+		ms := time.Duration(n) * time.Millisecond
+		switch args[i-1] {
+		case "wtime":
+			limits.WhiteTime = ms
+		case "btime":
+			limits.BlackTime = ms
+		case "winc":
+			limits.WhiteIncrement = ms
+		case "binc":
+			limits.BlackIncrement = ms
+		case "movestogo":
+			limits.MovesToGo = int(n)
+		case "depth":
+			limits.Depth = int(n)
+		case "nodes":
+			limits.Nodes = uint64(n)
+		case "movetime":
+			limits.MoveTime = ms
+		case "mate":
+			mate = int(n)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &search{
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		release: make(chan struct{}),
+		limits:  limits,
+		color:   e.game.Board().ToMove(),
+	}
+	e.search = s
+
+	// When pondering, the time limits are used after ponderhit
+	searchLimits := limits
+	if ponder {
+		searchLimits = chess.SearchLimits{Depth: limits.Depth, Nodes: limits.Nodes, Infinite: limits.Infinite}
+	}
+	if !ponder && !limits.Infinite {
+		s.releaseBestMove()
+	}
+
+	g := e.game
+	go func() {
+		defer close(s.done)
+
+		var result chess.SearchResult
+		if mate > 0 {
+			result = e.mateSearch(ctx, g, mate)
+		} else {
+			result = e.searcher.Think(ctx, g, searchLimits, e.writeInfo)
+		}
+
+		// In ponder and infinite mode, the best move is
+		// not written until stop or ponderhit
+		<-s.release
+		e.writeBestMove(result)
+	}()
+}
+
+// mateSearch searches for a mate in n moves, and
+// falls back to a normal search if there is none
+func (e *Engine) mateSearch(ctx context.Context, g *chess.Game, n int) chess.SearchResult {
+	start := time.Now()
+	r := chess.FindMate(ctx, g.Board(), n)
+	if !r.Found {
+		return e.searcher.Think(ctx, g, chess.SearchLimits{Depth: 2 * n}, e.writeInfo)
+	}
+
+	elapsed := time.Since(start)
+	e.writeLine(fmt.Sprintf("info depth %d score mate %d nodes %d time %d pv %s",
+		2*r.Moves-1, r.Moves, r.Nodes, elapsed.Milliseconds(), movesToString(r.PV)))
+
+	return chess.SearchResult{Move: r.PV[0], PV: r.PV, Depth: 2*r.Moves - 1, Nodes: r.Nodes}
+}
+
+// stop stops the running search, if any, and waits for the best move to be written
+func (e *Engine) stop() {
+	s := e.search
+	if s == nil {
+		return
+	}
+
+	s.cancel()
+	s.releaseBestMove()
+	<-s.done
+	e.search = nil
+}
+
+// ponderHit is called when the opponent played the expected move,
+// the ponder search continues as a normal search with the time
+// limits of the go command
+func (e *Engine) ponderHit() {
+	s := e.search
+	if s == nil || s.released || s.limits.Infinite {
+		return
+	}
+
+	if budget := s.limits.TimeBudget(s.color); budget > 0 {
+		time.AfterFunc(budget, s.cancel)
+	}
+	s.releaseBestMove()
+}
+
+// setOption sets an option : setoption name <id> [value <x>]
+func (e *Engine) setOption(args []string) {
+	i := indexOf(args, "value")
+	if len(args) == 0 || args[0] != "name" || i == 1 {
+		e.writeLine("info string invalid setoption")
+		return
+	}
+
+	name, value := "", ""
+	if i < 0 {
+		name = strings.Join(args[1:], " ")
+	} else {
+		name, value = strings.Join(args[1:i], " "), strings.Join(args[i+1:], " ")
+	}
+
+	switch strings.ToLower(name) {
+	case "hash":
+		if n, ok := e.parseSpin(name, value, 1, maxHashSize); ok {
+			e.stop()
+			e.searcher.SetHashSize(n)
+		}
+	case "threads":
+		if n, ok := e.parseSpin(name, value, 1, chess.MaxThreads); ok {
+			e.stop()
+			e.searcher.SetThreads(n)
+		}
+	case "multipv":
+		if n, ok := e.parseSpin(name, value, 1, maxMultiPV); ok {
+			e.stop()
+			e.searcher.SetMultiPV(n)
+		}
+	case "ponder":
+		// Pondering is controlled by the GUI with go ponder
+	default:
+		e.writeLine("info string unknown option " + name)
+	}
+}
+
+// parseSpin parses the value of a spin option
+func (e *Engine) parseSpin(name, value string, min, max int) (int, bool) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		e.writeLine(fmt.Sprintf("info string invalid value %s for %s", value, name))
+		return 0, false
+	}
+	return n, true
+}
+
+// writeInfo writes the search info, one line per principal variation
+func (e *Engine) writeInfo(info chess.SearchInfo) {
+	common := fmt.Sprintf("nodes %d nps %d time %d hashfull %d",
+		info.Nodes, info.NPS, info.Time.Milliseconds(), info.Hashfull)
+
+	if len(info.Lines) == 0 {
+		bound := ""
+		if info.LowerBound {
+			bound = " lowerbound"
+		} else if info.UpperBound {
+			bound = " upperbound"
+		}
+		e.writeLine(fmt.Sprintf("info depth %d score %s%s %s pv %s",
+			info.Depth, formatScore(info.Score), bound, common, movesToString(info.PV)))
+		return
+	}
+
+	multiPV := e.searcher.MultiPV() > 1
+	for i, line := range info.Lines {
+		s := fmt.Sprintf("info depth %d", line.Depth)
+		if multiPV {
+			s += fmt.Sprintf(" multipv %d", i+1)
+		}
+		e.writeLine(fmt.Sprintf("%s score %s %s pv %s", s, formatScore(line.Score), common, movesToString(line.PV)))
+	}
+}
+
+func (e *Engine) writeBestMove(r chess.SearchResult) {
+	if r.Move == chess.NoMove {
+		e.writeLine("bestmove 0000")
+		return
+	}
+
+	s := "bestmove " + r.Move.String()
+	if len(r.PV) > 1 {
+		s += " ponder " + r.PV[1].String()
+	}
+	e.writeLine(s)
+}
+
+func (e *Engine) writeLine(s string) {
+	e.outLock.Lock()
+	defer e.outLock.Unlock()
+
+	_, _ = fmt.Fprintln(e.out, s)
+}
+
+// releaseBestMove lets the best move be written when the search is done
+func (s *search) releaseBestMove() {
+	if !s.released {
+		s.released = true
+		close(s.release)
+	}
+}
+
+// formatScore returns the score as "cp <x>" or "mate <y>"
+func formatScore(score int) string {
+	if moves, ok := chess.MateIn(score); ok {
+		return fmt.Sprintf("mate %d", moves)
+	}
+	return fmt.Sprintf("cp %d", score)
+}
+
+func movesToString(moves []chess.Move) string {
+	s := make([]string, len(moves))
+	for i, m := range moves {
+		s[i] = m.String()
+	}
+	return strings.Join(s, " ")
+}
+
+func isMove(s string) bool {
+	_, err := chess.ParseMove(s)
+	return err == nil
+}
+
+func indexOf(args []string, s string) int {
+	for i, arg := range args {
+		if arg == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,233 @@
+package chess_uci
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// session runs an engine connected to pipes, so that
+// tests can send commands and read the responses
+type session struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan string
+	done  chan error
+}
+
+func newSession(t *testing.T) *session {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	s := &session{t: t, in: inWriter, lines: make(chan string, 1000), done: make(chan error, 1)}
+
+	go func() {
+		s.done <- New(inReader, outWriter).Run()
+		_ = outWriter.Close()
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outReader)
+		for scanner.Scan() {
+			s.lines <- scanner.Text()
+		}
+		close(s.lines)
+	}()
+
+	return s
+}
+
+func (s *session) send(commands ...string) {
+	for _, c := range commands {
+		_, err := io.WriteString(s.in, c+"\n")
+		assert.Nil(s.t, err)
+	}
+}
+
+// expect reads lines until a line starting with prefix is found,
+// and returns all lines read
+func (s *session) expect(prefix string) []string {
+	var lines []string
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				s.t.Fatalf("output ended while waiting for %q, got %q", prefix, lines)
+			}
+			lines = append(lines, line)
+			if strings.HasPrefix(line, prefix) {
+				return lines
+			}
+		case <-timeout:
+			s.t.Fatalf("timeout while waiting for %q, got %q", prefix, lines)
+		}
+	}
+}
+
+// expectNone makes sure that no line starting with prefix is written for a while
+func (s *session) expectNone(prefix string, d time.Duration) {
+	timeout := time.After(d)
+	for {
+		select {
+		case line := <-s.lines:
+			assert.False(s.t, strings.HasPrefix(line, prefix), "unexpected %q", line)
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func (s *session) quit() {
+	s.send("quit")
+	select {
+	case err := <-s.done:
+		assert.Nil(s.t, err)
+	case <-time.After(10 * time.Second):
+		s.t.Fatal("engine did not quit")
+	}
+}
+
+func TestUCI_Handshake(t *testing.T) {
+	s := newSession(t)
+	s.send("uci")
+	lines := s.expect("uciok")
+	assert.Equal(t, "id name chess", lines[0])
+	assert.Contains(t, lines, "option name Hash type spin default 16 min 1 max 4096")
+	assert.Contains(t, lines, "option name Threads type spin default 1 min 1 max 256")
+	assert.Contains(t, lines, "option name MultiPV type spin default 1 min 1 max 256")
+
+	s.send("isready")
+	assert.Equal(t, []string{"readyok"}, s.expect("readyok"))
+	s.quit()
+}
+
+func TestUCI_GoDepth(t *testing.T) {
+	s := newSession(t)
+	s.send("ucinewgame", "position startpos moves e2e4 e7e5 g1f3", "go depth 3")
+	lines := s.expect("bestmove")
+	assert.True(t, strings.HasPrefix(lines[0], "info depth 1 score cp "), lines[0])
+	assert.Contains(t, lines[len(lines)-2], "info depth 3 ")
+	assert.Contains(t, lines[len(lines)-2], " nodes ")
+	assert.Contains(t, lines[len(lines)-2], " pv ")
+	s.quit()
+}
+
+func TestUCI_PositionFEN(t *testing.T) {
+	s := newSession(t)
+	s.send("position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "go depth 2")
+	lines := s.expect("bestmove")
+	assert.Equal(t, "bestmove a1a8", lines[len(lines)-1])
+	assert.Contains(t, lines[len(lines)-2], "score mate 1")
+
+	// The moves are played from the FEN position
+	s.send("position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1 moves a1a2 g8h8", "go depth 2")
+	lines = s.expect("bestmove")
+	assert.Equal(t, "bestmove a2a8", lines[len(lines)-1])
+	s.quit()
+}
+
+func TestUCI_InvalidCommands(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos moves e2e5")
+	assert.Equal(t, []string{"info string invalid move e2e5 : illegal move"}, s.expect("info string"))
+	s.send("position fen 8/8 w")
+	s.expect("info string invalid fen")
+	s.send("setoption name Hash value 0")
+	s.expect("info string invalid value 0 for Hash")
+	s.send("setoption name Foo value 1")
+	s.expect("info string unknown option Foo")
+	s.send("foo")
+	s.expect("info string unknown command foo")
+	s.quit()
+}
+
+func TestUCI_Stop(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos", "go infinite")
+	s.expect("info depth 2")
+	s.expectNone("bestmove", 100*time.Millisecond)
+	s.send("stop")
+	s.expect("bestmove")
+	s.send("isready")
+	s.expect("readyok")
+	s.quit()
+}
+
+func TestUCI_GoMoveTime(t *testing.T) {
+	s := newSession(t)
+	start := time.Now()
+	s.send("position startpos", "go movetime 200")
+	s.expect("bestmove")
+	assert.Less(t, int64(time.Since(start)), int64(2*time.Second))
+	s.quit()
+}
+
+func TestUCI_GoClock(t *testing.T) {
+	s := newSession(t)
+	start := time.Now()
+	s.send("position startpos moves e2e4", "go wtime 1000 btime 3000 winc 0 binc 100 movestogo 10")
+	s.expect("bestmove")
+	assert.Less(t, int64(time.Since(start)), int64(2*time.Second))
+	s.quit()
+}
+
+func TestUCI_GoNodes(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos", "go nodes 3000")
+	s.expect("bestmove")
+	s.quit()
+}
+
+func TestUCI_PonderHit(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos moves e2e4 e7e5", "go ponder movetime 100")
+	s.expect("info depth 2")
+	s.expectNone("bestmove", 300*time.Millisecond)
+	s.send("ponderhit")
+	s.expect("bestmove")
+	s.quit()
+}
+
+func TestUCI_MultiPV(t *testing.T) {
+	s := newSession(t)
+	s.send("setoption name MultiPV value 3", "position startpos", "go depth 2")
+	lines := s.expect("bestmove")
+	var last []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "info depth 2 multipv") {
+			last = append(last, line)
+		}
+	}
+	assert.Len(t, last, 3)
+	for i, line := range last {
+		assert.Contains(t, line, []string{"multipv 1 ", "multipv 2 ", "multipv 3 "}[i])
+	}
+	s.quit()
+}
+
+func TestUCI_GoMate(t *testing.T) {
+	s := newSession(t)
+	s.send("position fen 6k1/8/8/8/8/8/8/RR4K1 w - - 0 1", "go mate 2")
+	lines := s.expect("bestmove")
+	assert.Contains(t, lines[len(lines)-2], "score mate 2")
+	s.quit()
+}
+
+func TestUCI_Threads(t *testing.T) {
+	s := newSession(t)
+	s.send("setoption name Threads value 2", "setoption name Hash value 1", "position startpos", "go depth 4")
+	s.expect("bestmove")
+	s.quit()
+}
+
+func TestUCI_EndOfInput(t *testing.T) {
+	// A piped search is finished before the engine exits
+	var out strings.Builder
+	err := New(strings.NewReader("position startpos\ngo depth 3\n"), &out).Run()
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "info depth 3 ")
+	assert.Contains(t, out.String(), "bestmove ")
+}
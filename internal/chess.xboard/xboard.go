@@ -0,0 +1,397 @@
+package chess_xboard
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	chess "github.com/hultan/chess/internal/chess.engine"
+)
+
+const engineName = "chess"
+
+// defaultMoveTime is the time per move when the GUI has sent no time control
+const defaultMoveTime = 5 * time.Second
+
+// mateScore is how mate scores are shown in the thinking output,
+// a mate in n moves is shown as mateScore+n
+const mateScore = 100000
+
+// Engine speaks the XBoard protocol (CECP) version 2, reading commands from
+// in and writing responses to out : https://www.gnu.org/software/xboard/engine-intf.html
+//  - side     - the color the engine plays, ColorNone in force mode
+//  - post     - 1 if thinking output is written
+//  - moves    - moves per time control, 0 if the whole game is one time control
+//  - base     - the time for each time control
+//  - inc      - the increment per move
+//  - moveTime - the exact time per move (st), 0 if not used
+//  - depth    - the maximum depth (sd), 0 if not used
+//  - engineTime, opponentTime - the clocks, set by the time and otim commands
+type Engine struct {
+	in       io.Reader
+	out      io.Writer
+	outLock  sync.Mutex
+	searcher *chess.Searcher
+	game     *chess.Game
+	search   *search
+
+	side         chess.Color
+	post         int32
+	moves        int
+	base         time.Duration
+	inc          time.Duration
+	moveTime     time.Duration
+	depth        int
+	engineTime   time.Duration
+	opponentTime time.Duration
+}
+
+// search is a running search, the best move is played when it is done
+//  - aborted - true if the search was aborted, and the best move is not played
+//  - lock    - protects aborted, and is held while the best move is played
+type search struct {
+	cancel  context.CancelFunc
+	done    chan struct{}
+	lock    sync.Mutex
+	aborted bool
+}
+
+// New creates a new XBoard engine
+func New(in io.Reader, out io.Writer) *Engine {
+	e := &Engine{in: in, out: out, searcher: chess.NewSearcher()}
+	e.newGame()
+	return e
+}
+
+// Run reads and executes commands until the quit command
+// is received or the input ends
+func (e *Engine) Run() error {
+	scanner := bufio.NewScanner(e.in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" {
+			e.abort()
+			return nil
+		}
+		e.execute(fields[0], fields[1:])
+	}
+
+	// Let a running search finish when the input ends, so that commands
+	// can be piped to the engine
+	if e.search != nil && scanner.Err() == nil {
+		<-e.search.done
+	}
+	e.abort()
+
+	return scanner.Err()
+}
+
+//
+// Private functions
+//
+
+func (e *Engine) execute(command string, args []string) {
+	switch command {
+	case "xboard", "accepted", "rejected", "random", "hard", "easy", "computer", "name", "rating", "ics":
+		// Nothing to do
+	case "protover":
+		e.writeLine(fmt.Sprintf(`feature myname="%s" ping=1 setboard=1 usermove=1 playother=1 san=0 colors=0 sigint=0 sigterm=0 analyze=0 done=1`, engineName))
+	case "ping":
+		e.writeLine("pong " + strings.Join(args, " "))
+	case "new":
+		e.abort()
+		e.newGame()
+	case "force":
+		e.abort()
+		e.side = chess.ColorNone
+	case "go":
+		e.abort()
+		e.side = e.game.Board().ToMove()
+		e.think()
+	case "playother":
+		e.abort()
+		e.side = opponent(e.game.Board().ToMove())
+	case "?":
+		e.stop()
+	case "usermove":
+		if len(args) == 1 {
+			e.userMove(args[0])
+		}
+	case "setboard":
+		e.abort()
+		e.setBoard(strings.Join(args, " "))
+	case "undo":
+		e.abort()
+		e.game.Undo()
+	case "remove":
+		e.abort()
+		e.game.Undo()
+		e.game.Undo()
+	case "result":
+		e.abort()
+		e.side = chess.ColorNone
+	case "level":
+		e.level(args)
+	case "st":
+		if n, err := strconv.Atoi(strings.Join(args, "")); err == nil && n > 0 {
+			e.moveTime = time.Duration(n) * time.Second
+		}
+	case "sd":
+		if n, err := strconv.Atoi(strings.Join(args, "")); err == nil && n > 0 {
+			e.depth = n
+		}
+	case "time":
+		e.engineTime = centiseconds(args)
+	case "otim":
+		e.opponentTime = centiseconds(args)
+	case "post":
+		atomic.StoreInt32(&e.post, 1)
+	case "nopost":
+		atomic.StoreInt32(&e.post, 0)
+	default:
+		// Moves are accepted without the usermove prefix as well
+		if _, err := chess.ParseMove(command); err == nil {
+			e.userMove(command)
+			return
+		}
+		e.writeLine("Error (unknown command): " + command)
+	}
+}
+
+// newGame sets up the start position, with the engine playing black, and
+// resets the depth limit
+func (e *Engine) newGame() {
+	e.game = chess.NewGame(chess.NewBoard(true))
+	e.side = chess.ColorBlack
+	e.depth = 0
+	e.searcher.ClearHash()
+}
+
+func (e *Engine) setBoard(fen string) {
+	b, err := chess.FromFEN(fen)
+	if err != nil {
+		e.writeLine("tellusererror Illegal position")
+		return
+	}
+	e.game = chess.NewGame(b)
+}
+
+// userMove plays the opponent's move, and starts thinking if
+// it is the engine's turn
+func (e *Engine) userMove(s string) {
+	e.abort()
+
+	m, err := chess.ParseMove(s)
+	if err == nil {
+		err = e.game.Play(m)
+	}
+	if err != nil {
+		e.writeLine("Illegal move: " + s)
+		return
+	}
+
+	if e.gameOver() {
+		return
+	}
+	if e.game.Board().ToMove() == e.side {
+		e.think()
+	}
+}
+
+// level sets a conventional or incremental time control : level MPS BASE INC,
+// where BASE is in minutes (i e "5" or "0:30") and INC in seconds
+func (e *Engine) level(args []string) {
+	if len(args) != 3 {
+		e.writeLine("Error (invalid level): " + strings.Join(args, " "))
+		return
+	}
+
+	moves, err1 := strconv.Atoi(args[0])
+	base, err2 := parseMinutes(args[1])
+	inc, err3 := strconv.ParseFloat(args[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		e.writeLine("Error (invalid level): " + strings.Join(args, " "))
+		return
+	}
+
+	e.moves, e.base, e.inc = moves, base, time.Duration(inc*float64(time.Second))
+	e.engineTime, e.opponentTime = base, base
+	e.moveTime = 0
+}
+
+// think starts a search, the best move is played when it is done
+func (e *Engine) think() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &search{cancel: cancel, done: make(chan struct{})}
+	e.search = s
+
+	g, limits := e.game, e.limits()
+	go func() {
+		defer close(s.done)
+
+		r := e.searcher.Think(ctx, g, limits, e.writeThinking)
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if s.aborted || r.Move == chess.NoMove {
+			return
+		}
+		if err := g.Play(r.Move); err != nil {
+			e.writeLine("Error (illegal engine move): " + r.Move.String())
+			return
+		}
+		e.writeLine("move " + r.Move.String())
+		e.gameOver()
+	}()
+}
+
+// limits returns the search limits from the time control
+func (e *Engine) limits() chess.SearchLimits {
+	limits := chess.SearchLimits{Depth: e.depth, MoveTime: e.moveTime}
+	if e.moveTime > 0 {
+		return limits
+	}
+
+	// Without a time control, the search is limited by the depth (sd),
+	// or by the default time per move
+	if e.engineTime == 0 && e.inc == 0 {
+		if e.depth == 0 {
+			limits.MoveTime = defaultMoveTime
+		}
+		return limits
+	}
+
+	if e.side == chess.ColorWhite {
+		limits.WhiteTime, limits.BlackTime = e.engineTime, e.opponentTime
+		limits.WhiteIncrement, limits.BlackIncrement = e.inc, e.inc
+	} else {
+		limits.WhiteTime, limits.BlackTime = e.opponentTime, e.engineTime
+		limits.WhiteIncrement, limits.BlackIncrement = e.inc, e.inc
+	}
+
+	// Moves left to the next time control, the current move included
+	if e.moves > 0 {
+		played := (e.game.Board().MoveCount() - 1) % e.moves
+		limits.MovesToGo = e.moves - played
+	}
+
+	return limits
+}
+
+// stop stops the running search, if any, and waits for the move to be played
+func (e *Engine) stop() {
+	s := e.search
+	if s == nil {
+		return
+	}
+
+	s.cancel()
+	<-s.done
+	e.search = nil
+}
+
+// abort stops the running search, if any, without playing its move
+func (e *Engine) abort() {
+	s := e.search
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	s.aborted = true
+	s.lock.Unlock()
+	e.stop()
+}
+
+// gameOver writes the result and returns true if the game is over
+func (e *Engine) gameOver() bool {
+	status := e.game.Status()
+	if !status.IsOver() {
+		return false
+	}
+
+	switch {
+	case status.IsDraw():
+		e.writeLine("1/2-1/2 {" + status.String() + "}")
+	case e.game.Board().ToMove() == chess.ColorBlack:
+		e.writeLine("1-0 {White mates}")
+	default:
+		e.writeLine("0-1 {Black mates}")
+	}
+	return true
+}
+
+// writeThinking writes the thinking output : ply score time nodes pv,
+// where the time is in centiseconds
+func (e *Engine) writeThinking(info chess.SearchInfo) {
+	if atomic.LoadInt32(&e.post) == 0 || info.LowerBound || info.UpperBound {
+		return
+	}
+
+	score := info.Score
+	if moves, ok := chess.MateIn(score); ok {
+		if moves > 0 {
+			score = mateScore + moves
+		} else {
+			score = -mateScore + moves
+		}
+	}
+
+	pv := make([]string, len(info.PV))
+	for i, m := range info.PV {
+		pv[i] = m.String()
+	}
+	e.writeLine(fmt.Sprintf("%d %d %d %d %s", info.Depth, score, info.Time.Milliseconds()/10, info.Nodes, strings.Join(pv, " ")))
+}
+
+func (e *Engine) writeLine(s string) {
+	e.outLock.Lock()
+	defer e.outLock.Unlock()
+
+	_, _ = fmt.Fprintln(e.out, s)
+}
+
+// parseMinutes parses a time in minutes, or minutes and seconds (i e "5" or "0:30")
+func parseMinutes(s string) (time.Duration, error) {
+	parts := strings.SplitN(s, ":", 2)
+	minutes, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, err
+	}
+	d := time.Duration(minutes) * time.Minute
+	if len(parts) == 2 {
+		seconds, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(seconds) * time.Second
+	}
+	return d, nil
+}
+
+// centiseconds parses a time in centiseconds
+func centiseconds(args []string) time.Duration {
+	if len(args) != 1 {
+		return 0
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0
+	}
+	return time.Duration(n) * 10 * time.Millisecond
+}
+
+func opponent(c chess.Color) chess.Color {
+	if c == chess.ColorWhite {
+		return chess.ColorBlack
+	}
+	return chess.ColorWhite
+}
@@ -0,0 +1,293 @@
+package chess_xboard
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// session runs an engine connected to pipes, so that
+// tests can send commands and read the responses
+type session struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan string
+	done  chan error
+}
+
+func newSession(t *testing.T) *session {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	s := &session{t: t, in: inWriter, lines: make(chan string, 1000), done: make(chan error, 1)}
+
+	go func() {
+		s.done <- New(inReader, outWriter).Run()
+		_ = outWriter.Close()
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outReader)
+		for scanner.Scan() {
+			s.lines <- scanner.Text()
+		}
+		close(s.lines)
+	}()
+
+	s.send("xboard", "protover 2")
+	s.expect("feature")
+	return s
+}
+
+func (s *session) send(commands ...string) {
+	for _, c := range commands {
+		_, err := io.WriteString(s.in, c+"\n")
+		assert.Nil(s.t, err)
+	}
+}
+
+// expect reads lines until a line starting with prefix is found,
+// and returns all lines read
+func (s *session) expect(prefix string) []string {
+	var lines []string
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				s.t.Fatalf("output ended while waiting for %q, got %q", prefix, lines)
+			}
+			lines = append(lines, line)
+			if strings.HasPrefix(line, prefix) {
+				return lines
+			}
+		case <-timeout:
+			s.t.Fatalf("timeout while waiting for %q, got %q", prefix, lines)
+		}
+	}
+}
+
+// sync waits until all commands sent so far have been executed
+func (s *session) sync() []string {
+	s.send("ping 42")
+	return s.expect("pong 42")
+}
+
+func (s *session) quit() {
+	s.send("quit")
+	select {
+	case err := <-s.done:
+		assert.Nil(s.t, err)
+	case <-time.After(10 * time.Second):
+		s.t.Fatal("engine did not quit")
+	}
+}
+
+func TestXBoard_Features(t *testing.T) {
+	inReader, inWriter := io.Pipe()
+	var out strings.Builder
+	done := make(chan struct{})
+	go func() {
+		_ = New(inReader, &out).Run()
+		close(done)
+	}()
+	_, _ = io.WriteString(inWriter, "xboard\nprotover 2\nquit\n")
+	<-done
+
+	features := out.String()
+	for _, f := range []string{"ping=1", "setboard=1", "usermove=1", "playother=1", "sigint=0", "done=1"} {
+		assert.Contains(t, features, f)
+	}
+}
+
+func TestXBoard_EnginePlaysBlack(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "sd 3", "usermove e2e4")
+	lines := s.expect("move ")
+	assert.Len(t, lines, 1)
+
+	// Thinking output is written after post
+	s.send("post", "usermove d2d4")
+	lines = s.expect("move ")
+	assert.Len(t, lines, 4)
+	fields := strings.Fields(lines[0])
+	assert.Equal(t, "1", fields[0])
+	assert.GreaterOrEqual(t, len(fields), 5)
+	s.quit()
+}
+
+func TestXBoard_ForceAndGo(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "force", "sd 2", "usermove e2e4", "usermove e7e5")
+	lines := s.sync()
+	assert.Equal(t, []string{"pong 42"}, lines)
+
+	// The engine plays white, the side to move
+	s.send("go")
+	s.expect("move ")
+	s.send("usermove b8c6")
+	s.expect("move ")
+	s.quit()
+}
+
+func TestXBoard_PlayOther(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "force", "sd 2", "usermove e2e4", "playother")
+	assert.Equal(t, []string{"pong 42"}, s.sync())
+	s.send("usermove e7e5")
+	s.expect("move ")
+	s.quit()
+}
+
+func TestXBoard_SetBoard(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "force", "setboard 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "sd 2", "go")
+	assert.Equal(t, []string{"move a1a8", "1-0 {White mates}"}, s.expect("1-0"))
+
+	s.send("setboard 8/8 w")
+	s.expect("tellusererror Illegal position")
+	s.quit()
+}
+
+func TestXBoard_IllegalMove(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "usermove e2e5")
+	assert.Equal(t, []string{"Illegal move: e2e5"}, s.expect("Illegal move"))
+	s.send("foo")
+	s.expect("Error (unknown command): foo")
+	s.quit()
+}
+
+func TestXBoard_UndoRemove(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "force", "e2e4", "e7e5", "g1f3", "remove", "undo")
+	assert.Equal(t, []string{"pong 42"}, s.sync())
+
+	// After e2e4 was undone, it is white's turn in the start position
+	s.send("usermove e7e5")
+	s.expect("Illegal move: e7e5")
+	s.send("usermove e2e4")
+	assert.Equal(t, []string{"pong 42"}, s.sync())
+	s.quit()
+}
+
+func TestXBoard_MoveNow(t *testing.T) {
+	s := newSession(t)
+	start := time.Now()
+	s.send("new", "level 0 60 0", "time 600000", "otim 600000", "usermove e2e4")
+	time.Sleep(100 * time.Millisecond)
+	s.send("?")
+	s.expect("move ")
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	s.quit()
+}
+
+func TestXBoard_ForceAbortsSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+	}{
+		{"force", "force"},
+		{"result", "result 1-0 {White resigns}"},
+		{"new", "new"},
+		{"undo", "undo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSession(t)
+			s.send("new", "level 0 60 0", "time 600000", "otim 600000", "usermove e2e4")
+			time.Sleep(100 * time.Millisecond)
+
+			// The search is stopped without playing a move
+			s.send(tt.command)
+			assert.Equal(t, []string{"pong 42"}, s.sync())
+			s.quit()
+		})
+	}
+}
+
+func TestXBoard_QuitAbortsSearch(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "force", "level 40 60 0", "time 360000", "otim 360000", "go")
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	s.send("quit")
+	select {
+	case err := <-s.done:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("engine did not quit")
+	}
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestXBoard_TimeControls(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+	}{
+		{"st", []string{"st 1"}},
+		{"level", []string{"level 40 0:30 0", "time 3000", "otim 3000"}},
+		{"incremental", []string{"level 0 1 2", "time 500", "otim 500"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSession(t)
+			start := time.Now()
+			s.send("new")
+			s.send(tt.commands...)
+			s.send("usermove e2e4")
+			s.expect("move ")
+			assert.Less(t, int64(time.Since(start)), int64(3*time.Second))
+			s.quit()
+		})
+	}
+}
+
+func TestXBoard_Limits(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		depth    int
+		moveTime time.Duration
+		timeLeft time.Duration
+	}{
+		{"No time control", nil, 0, defaultMoveTime, 0},
+		{"sd", []string{"sd 4"}, 4, 0, 0},
+		{"st", []string{"st 2"}, 0, 2 * time.Second, 0},
+		{"level", []string{"level 40 5 0"}, 0, 0, 5 * time.Minute},
+		{"time", []string{"level 40 5 0", "time 1000"}, 0, 0, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New(strings.NewReader(""), io.Discard)
+			for _, c := range tt.commands {
+				fields := strings.Fields(c)
+				e.execute(fields[0], fields[1:])
+			}
+			limits := e.limits()
+			assert.Equal(t, tt.depth, limits.Depth)
+			assert.Equal(t, tt.moveTime, limits.MoveTime)
+			assert.Equal(t, tt.timeLeft, limits.BlackTime)
+		})
+	}
+}
+
+func TestXBoard_Result(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "sd 2", "result 1-0 {White resigns}", "usermove e2e4")
+	// The engine doesn't play after the game is over
+	assert.Equal(t, []string{"pong 42"}, s.sync())
+	s.quit()
+}
+
+func TestXBoard_Mate(t *testing.T) {
+	s := newSession(t)
+	s.send("new", "force", "setboard 6k1/8/8/8/8/8/8/RR4K1 w - - 0 1", "post", "sd 4", "go")
+	lines := s.expect("move ")
+	assert.True(t, strings.HasPrefix(lines[len(lines)-2], "4 100002 "), lines[len(lines)-2])
+	s.quit()
+}